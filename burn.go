@@ -0,0 +1,176 @@
+package gnco
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco/orbits"
+)
+
+// BurnFrame is the frame in which the change of velocity of a [Burn] is given.
+// Unlike [Frame] burn frames are defined by the inertial state of the body.
+type BurnFrame uint8
+
+const (
+	_ BurnFrame = iota
+	// BurnFrameInertial is the inertial frame.
+	BurnFrameInertial
+	// BurnFrameGeographic is the geographic (North, East, Down) frame at the body's position.
+	BurnFrameGeographic
+	// BurnFrameVelocity is the velocity frame of [TVGFromVelocity] with its X axis along the inertial velocity of the body.
+	BurnFrameVelocity
+	// BurnFrameRSW is the orbital frame of the body, see [orbits.TRSW].
+	BurnFrameRSW
+)
+
+// IsValid reports whether bf is one of the defined burn frames.
+func (bf BurnFrame) IsValid() bool {
+	return bf >= BurnFrameInertial && bf <= BurnFrameRSW
+}
+
+// Burn is a change of velocity of the body scheduled on a [PhysicsPointIntegrator] at an exact epoch time.
+type Burn struct {
+	// T is the epoch time at which the burn starts [s].
+	T float64
+	// Frame in which DeltaV is given.
+	Frame BurnFrame
+	// DeltaV is the change of velocity [m/s].
+	DeltaV md3.Vec
+	// Duration of a finite burn [s]. The change of velocity is spread over the burn as a constant
+	// acceleration DeltaV/Duration held fixed in Frame, so the direction follows the rotation of
+	// velocity and RSW frames. If zero the burn is impulsive.
+	Duration float64
+}
+
+// End returns the epoch time at which the burn ends [s].
+func (b Burn) End() float64 { return b.T + b.Duration }
+
+// ScheduleBurn schedules b to be applied by [PhysicsPointIntegrator.Step]. Steps are split at the start and end
+// of burns so impulses are applied exactly at their epoch time and finite burns start and stop at step boundaries.
+// Burns are applied every time the integrator steps forward across them, so rewinding the state with
+// [PhysicsPointIntegrator.SetState] and stepping again reproduces the trajectory. They are ignored when stepping
+// backwards. An impulsive burn scheduled at the current epoch time is applied immediately.
+// The state transition matrix does not account for burns.
+func (phys *PhysicsPointIntegrator) ScheduleBurn(b Burn) {
+	t, SBI, VBI := phys.integrator.State()
+	switch {
+	case b.Duration < 0 || math.IsNaN(b.Duration):
+		panic("negative burn duration")
+	case b.T < t:
+		panic("burn scheduled before current epoch time")
+	case !b.Frame.IsValid():
+		panic("invalid burn frame")
+	}
+	phys.burns = append(phys.burns, b)
+	if b.T == t && b.Duration == 0 {
+		phys.integrator.SetState(t, SBI, md3.Add(VBI, phys.burnInertial(b.Frame, t, SBI, VBI, b.DeltaV)))
+	}
+}
+
+// Burns appends the scheduled burns to dst in order of scheduling.
+func (phys *PhysicsPointIntegrator) Burns(dst []Burn) []Burn {
+	return append(dst, phys.burns...)
+}
+
+// ClearBurns removes all scheduled burns.
+func (phys *PhysicsPointIntegrator) ClearBurns() {
+	phys.burns = phys.burns[:0]
+}
+
+// ApplyDeltaV adds the impulsive change of velocity dv [m/s] given in frame F to the current state of the body
+// and returns the new inertial velocity.
+func (phys *PhysicsPointIntegrator) ApplyDeltaV(F BurnFrame, dv md3.Vec) (VBI md3.Vec) {
+	if !F.IsValid() {
+		panic("invalid burn frame")
+	}
+	t, SBI, VBI := phys.integrator.State()
+	VBI = md3.Add(VBI, phys.burnInertial(F, t, SBI, VBI, dv))
+	phys.integrator.SetState(t, SBI, VBI)
+	return VBI
+}
+
+// nextBurnEvent returns the earliest start or end of a burn within (t, tEnd]. ok is false if there is none.
+func (phys *PhysicsPointIntegrator) nextBurnEvent(t, tEnd float64) (next float64, ok bool) {
+	next = tEnd
+	for _, b := range phys.burns {
+		for _, tEvent := range [2]float64{b.T, b.End()} {
+			if tEvent > t && tEvent <= next {
+				next, ok = tEvent, true
+			}
+		}
+	}
+	return next, ok
+}
+
+// applyImpulses applies the impulsive burns at epoch time t to the integrator's state.
+func (phys *PhysicsPointIntegrator) applyImpulses(t float64) {
+	_, SBI, VBI := phys.integrator.State()
+	applied := false
+	for _, b := range phys.burns {
+		if b.Duration == 0 && b.T == t {
+			VBI = md3.Add(VBI, phys.burnInertial(b.Frame, t, SBI, VBI, b.DeltaV))
+			applied = true
+		}
+	}
+	if applied {
+		phys.integrator.SetState(t, SBI, VBI)
+	}
+}
+
+// activateBurns selects the finite burns acting during the step [t0, t0+h]. Steps are split at burn
+// boundaries so a burn acts during the whole step or not at all.
+func (phys *PhysicsPointIntegrator) activateBurns(t0, h float64) {
+	phys.activeBurns = phys.activeBurns[:0]
+	if h <= 0 {
+		return
+	}
+	tMid := t0 + h/2
+	for i, b := range phys.burns {
+		if b.Duration > 0 && tMid >= b.T && tMid < b.End() {
+			phys.activeBurns = append(phys.activeBurns, i)
+		}
+	}
+}
+
+// burnAccel returns the inertial acceleration of the active finite burns at state s.
+func (phys *PhysicsPointIntegrator) burnAccel(s *ForceState) (ABII md3.Vec) {
+	for _, i := range phys.activeBurns {
+		b := phys.burns[i]
+		ABII = md3.Add(ABII, burnToInertial(b.Frame, s, md3.Scale(1/b.Duration, b.DeltaV)))
+	}
+	return ABII
+}
+
+// burnAccelAt returns the inertial acceleration of the finite burns in progress at the epoch time of state s.
+// ok is false if no finite burn is in progress.
+func (phys *PhysicsPointIntegrator) burnAccelAt(s *ForceState) (ABII md3.Vec, ok bool) {
+	for _, b := range phys.burns {
+		if b.Duration > 0 && s.T >= b.T && s.T < b.End() {
+			ABII = md3.Add(ABII, burnToInertial(b.Frame, s, md3.Scale(1/b.Duration, b.DeltaV)))
+			ok = true
+		}
+	}
+	return ABII, ok
+}
+
+// burnInertial converts the change of velocity dv in frame F to inertial frame for a body at state (t, SBI, VBI).
+func (phys *PhysicsPointIntegrator) burnInertial(F BurnFrame, t float64, SBI, VBI, dv md3.Vec) md3.Vec {
+	s := phys.forceState(t, SBI, VBI)
+	return burnToInertial(F, &s, dv)
+}
+
+// burnToInertial converts vector v in frame F to inertial frame for a body at state s.
+func burnToInertial(F BurnFrame, s *ForceState, v md3.Vec) md3.Vec {
+	switch F {
+	case BurnFrameInertial:
+		return v
+	case BurnFrameRSW:
+		return md3.MulMatVecTrans(orbits.TRSW(s.SBI, s.VBI), v)
+	case BurnFrameGeographic:
+		return md3.MulMatVecTrans(s.TGI, v)
+	case BurnFrameVelocity:
+		orient := Orientation{TGI: s.TGI, TVG: TVGFromVelocity(md3.MulMatVec(s.TGI, s.VBI))}
+		return FrameVelocity.ToInertial(orient, v)
+	}
+	panic("unsupported burn frame")
+}
@@ -0,0 +1,176 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco/orbits"
+)
+
+func TestBurnHohmann(t *testing.T) {
+	earth := NewEarth()
+	mu := earth.G()
+	const r1, r2, t0 = 7000e3, 10000e3, 100.
+	from, _ := orbits.NewCircular(r1)
+	to, _ := orbits.NewCircular(r2)
+	m, err := orbits.Hohmann(from, to, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	v1 := math.Sqrt(mu / r1)
+	// Start a quarter period before the first burn so the body is at X when it burns.
+	tq := math.Pi / 2 * r1 / v1
+	phys := NewPhysicsPointIntegrator(&coords, t0-tq, md3.Vec{Y: -r1}, md3.Vec{X: v1})
+	for _, b := range m.Burns {
+		phys.ScheduleBurn(Burn{T: t0 + b.T, Frame: BurnFrameRSW, DeltaV: b.DeltaV})
+	}
+	// Steps deliberately not aligned with burn times.
+	tEnd := t0 + m.Duration() + 1000
+	var tc float64
+	var SBI, VBI md3.Vec
+	for tc < tEnd {
+		tc, SBI, VBI = phys.Step(math.Min(37, tEnd-tc), md3.Vec{})
+	}
+	if d := md3.Norm(SBI) - r2; math.Abs(d) > 1e-3 {
+		t.Errorf("final radius error %gm", d)
+	}
+	if d := md3.Norm(VBI) - math.Sqrt(mu/r2); math.Abs(d) > 1e-6 {
+		t.Errorf("final speed error %gm/s", d)
+	}
+
+	// Rewinding and stepping across the burns again reproduces the trajectory.
+	phys.SetState(t0-tq, md3.Vec{Y: -r1}, md3.Vec{X: v1})
+	SBI2, VBI2 := phys.Propagate(tEnd, 60)
+	if !md3.EqualElem(SBI, SBI2, 1e-3) || !md3.EqualElem(VBI, VBI2, 1e-6) {
+		t.Errorf("rewound trajectory differs: (%v,%v) vs (%v,%v)", SBI, VBI, SBI2, VBI2)
+	}
+}
+
+func TestBurnCWRendezvous(t *testing.T) {
+	// Deputy and chief integrated separately, relative state differenced in the chief's LVLH frame.
+	earth := NewEarth()
+	const radius = 7000e3
+	orbit, err := orbits.NewCircular(radius)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, vt := orbit.Velocity(earth.G(), 0)
+	n := orbit.MeanMotion(earth.G())
+	rc, vc := md3.Vec{X: radius}, md3.Vec{Y: vt * math.Cos(0.5), Z: vt * math.Sin(0.5)}
+	r0, v0 := md3.Vec{X: 500, Y: -3000, Z: 200}, md3.Vec{X: 0, Y: 0.5, Z: 0}
+	rd, vd := orbits.LVLHToInertial(rc, vc, r0, v0)
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	chief := NewPhysicsPointIntegrator(&coords, 0, rc, vc)
+	coords2 := coords
+	deputy := NewPhysicsPointIntegrator(&coords2, 0, rd, vd)
+	tof := 0.4 * 2 * math.Pi / n
+	dv0, dvf, err := orbits.CWRendezvous(n, r0, v0, md3.Vec{}, md3.Vec{}, tof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Burns are applied to the deputy in the chief's LVLH frame at the time of the burn.
+	deputy.ApplyDeltaV(BurnFrameInertial, md3.MulMatVecTrans(orbits.TRSW(rc, vc), dv0))
+	rc, vc = chief.Propagate(tof/2, 30)
+	rd, vd = deputy.Propagate(tof/2, 30)
+	r, v := orbits.RelativeToLVLH(rc, vc, rd, vd)
+	wantr, wantv := orbits.CWPropagate(n, r0, md3.Add(v0, dv0), tof/2)
+	if d := md3.Norm(md3.Sub(r, wantr)); d > 5 {
+		t.Errorf("CW position error %gm midway", d)
+	}
+	if d := md3.Norm(md3.Sub(v, wantv)); d > 5e-3 {
+		t.Errorf("CW velocity error %gm/s midway", d)
+	}
+	rc, vc = chief.Propagate(tof, 30)
+	deputy.Propagate(tof, 30)
+	_, rd, vd = deputy.State()
+	vd = deputy.ApplyDeltaV(BurnFrameInertial, md3.MulMatVecTrans(orbits.TRSW(rc, vc), dvf))
+	r, v = orbits.RelativeToLVLH(rc, vc, rd, vd)
+	if md3.Norm(r) > 10 || md3.Norm(v) > 0.02 {
+		t.Errorf("rendezvous miss: position %v, velocity %v", r, v)
+	}
+}
+
+func TestFiniteBurn(t *testing.T) {
+	earth := NewEarth()
+	SBI0, VBI0 := md3.Vec{X: 7000e3}, md3.Vec{Y: 100}
+	const tb, duration, dt = 5., 10., 4.
+	for _, frame := range []BurnFrame{BurnFrameInertial, BurnFrameVelocity} {
+		coords := earth.GeocentricFromDegrees(0, 0, 0)
+		phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+		phys.RemoveForceModel("gravity")
+		dv := md3.Vec{Y: 20}
+		if frame == BurnFrameVelocity {
+			dv = md3.Vec{X: 20} // Along velocity.
+		}
+		phys.ScheduleBurn(Burn{T: tb, Frame: frame, DeltaV: dv, Duration: duration})
+		var SBI, VBI md3.Vec
+		for i := 0; i < 5; i++ {
+			var tc float64
+			tc, SBI, VBI = phys.Step(dt, md3.Vec{})
+			// Burns in progress are reported as a force contribution.
+			forces := phys.Forces(nil)
+			if inProgress := tc >= tb && tc < tb+duration; !inProgress && len(forces) != 0 {
+				t.Errorf("frame %d: t=%g unexpected forces %v", frame, tc, forces)
+			} else if inProgress && (len(forces) != 1 || forces[0].Name != "burn" || math.Abs(md3.Norm(forces[0].AccelInertial)-20/duration) > 1e-12) {
+				t.Errorf("frame %d: t=%g want burn contribution, got %v", frame, tc, forces)
+			}
+		}
+		// Uniform acceleration between tb and tb+duration.
+		const a = 20 / duration
+		tEnd := 5 * dt
+		wantSBI := md3.Add(SBI0, md3.Vec{Y: 100*tEnd + a*duration*duration/2 + a*duration*(tEnd-tb-duration)})
+		wantVBI := md3.Vec{Y: 120}
+		if !md3.EqualElem(SBI, wantSBI, 1e-6) || !md3.EqualElem(VBI, wantVBI, 1e-9) {
+			t.Errorf("frame %d: want (%v,%v), got (%v,%v)", frame, wantSBI, wantVBI, SBI, VBI)
+		}
+	}
+}
+
+func TestApplyDeltaV(t *testing.T) {
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(30, 45, 0)
+	SBI, _ := coords.InertialCoords(100)
+	VBI := md3.Vec{X: 1000, Y: -2000, Z: 7000}
+	phys := NewPhysicsPointIntegrator(&coords, 100, SBI, VBI)
+	// Down in geographic frame is radially inward.
+	got := md3.Sub(phys.ApplyDeltaV(BurnFrameGeographic, md3.Vec{Z: 10}), VBI)
+	if want := md3.Scale(-10, md3.Unit(SBI)); !md3.EqualElem(got, want, 1e-9) {
+		t.Errorf("geographic down: want %v, got %v", want, got)
+	}
+	// Along track in RSW and forward in velocity frame coincide for a circular orbit.
+	phys.SetState(100, md3.Vec{X: 7000e3}, md3.Vec{Y: 7500})
+	vRSW := phys.ApplyDeltaV(BurnFrameRSW, md3.Vec{Y: 1})
+	vVel := phys.ApplyDeltaV(BurnFrameVelocity, md3.Vec{X: 1})
+	if !md3.EqualElem(vRSW, md3.Vec{Y: 7501}, 1e-9) || !md3.EqualElem(vVel, md3.Vec{Y: 7502}, 1e-9) {
+		t.Errorf("along track burns: got %v then %v", vRSW, vVel)
+	}
+	// Impulses scheduled at the current time are applied immediately.
+	phys.ScheduleBurn(Burn{T: 100, Frame: BurnFrameInertial, DeltaV: md3.Vec{Z: 5}})
+	if _, _, VBI := phys.State(); VBI.Z != 5 {
+		t.Errorf("immediate burn not applied, velocity %v", VBI)
+	}
+	if len(phys.Burns(nil)) != 1 {
+		t.Error("burn not scheduled")
+	}
+	phys.ClearBurns()
+	if len(phys.Burns(nil)) != 0 {
+		t.Error("burns not cleared")
+	}
+
+	// Invalid frames are rejected when scheduling instead of mid step.
+	for _, bad := range []Burn{{T: 200}, {T: 200, Frame: BurnFrameRSW + 1, Duration: 10}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic scheduling burn in frame %d", bad.Frame)
+				}
+			}()
+			phys.ScheduleBurn(bad)
+		}()
+	}
+	if len(phys.Burns(nil)) != 0 {
+		t.Error("invalid burn scheduled")
+	}
+}
@@ -0,0 +1,134 @@
+package control
+
+import "math"
+
+// Actuator models the dynamics of a control effector such as a fin servo.
+type Actuator interface {
+	// Update advances the actuator to time t with the given command held since the
+	// last update and returns the actuator position.
+	Update(t, command float64) float64
+	// Position returns the current actuator position.
+	Position() float64
+}
+
+var (
+	_ Actuator = (*FirstOrderActuator)(nil)
+	_ Actuator = (*SecondOrderActuator)(nil)
+)
+
+// Limits are the position and rate limits of an actuator. A limit is disabled when zero.
+type Limits struct {
+	MaxPosition float64 // Maximum absolute position.
+	MaxRate     float64 // Maximum absolute rate [s^-1].
+}
+
+func (l Limits) position(x float64) float64 {
+	if l.MaxPosition > 0 {
+		return math.Max(-l.MaxPosition, math.Min(x, l.MaxPosition))
+	}
+	return x
+}
+
+func (l Limits) rate(v float64) float64 {
+	if l.MaxRate > 0 {
+		return math.Max(-l.MaxRate, math.Min(v, l.MaxRate))
+	}
+	return v
+}
+
+// FirstOrderActuator has first order lag dynamics with the given time constant:
+//
+//	dx/dt = (command - x) / TimeConstant
+//
+// The dynamics are integrated with steps no larger than Period.
+type FirstOrderActuator struct {
+	TimeConstant float64 // [s]
+	Limits       Limits
+	Period       float64 // Integration step of the actuator dynamics [s].
+	x, t         float64
+	started      bool
+}
+
+// NewFirstOrderActuator returns a first order actuator integrated every period seconds.
+func NewFirstOrderActuator(timeConstant, period float64, limits Limits) *FirstOrderActuator {
+	return &FirstOrderActuator{TimeConstant: timeConstant, Limits: limits, Period: period}
+}
+
+// Update advances the actuator to time t. It implements [Actuator].
+func (a *FirstOrderActuator) Update(t, command float64) float64 {
+	command = a.Limits.position(command)
+	advance(&a.t, &a.started, t, a.Period, func(h float64) {
+		rate := a.Limits.rate((command - a.x) / a.TimeConstant)
+		// Do not overshoot command with large steps.
+		if math.Abs(rate*h) > math.Abs(command-a.x) {
+			rate = (command - a.x) / h
+		}
+		a.x = a.Limits.position(a.x + h*rate)
+	})
+	return a.x
+}
+
+// Position returns the actuator position. It implements [Actuator].
+func (a *FirstOrderActuator) Position() float64 { return a.x }
+
+// SecondOrderActuator has second order dynamics of natural frequency [rad/s] and damping ratio:
+//
+//	d²x/dt² = NaturalFrequency² * (command - x) - 2 * Damping * NaturalFrequency * dx/dt
+//
+// The dynamics are integrated with steps no larger than Period.
+type SecondOrderActuator struct {
+	NaturalFrequency float64 // [rad/s]
+	Damping          float64 // [Adim]
+	Limits           Limits
+	Period           float64 // Integration step of the actuator dynamics [s].
+	x, v, t          float64
+	started          bool
+}
+
+// NewSecondOrderActuator returns a second order actuator integrated every period seconds.
+func NewSecondOrderActuator(naturalFrequency, damping, period float64, limits Limits) *SecondOrderActuator {
+	return &SecondOrderActuator{NaturalFrequency: naturalFrequency, Damping: damping, Limits: limits, Period: period}
+}
+
+// Update advances the actuator to time t. It implements [Actuator].
+func (a *SecondOrderActuator) Update(t, command float64) float64 {
+	command = a.Limits.position(command)
+	wn := a.NaturalFrequency
+	advance(&a.t, &a.started, t, a.Period, func(h float64) {
+		// Semi-implicit Euler is stable for h*wn < 2.
+		accel := wn*wn*(command-a.x) - 2*a.Damping*wn*a.v
+		a.v = a.Limits.rate(a.v + h*accel)
+		x := a.x + h*a.v
+		a.x = a.Limits.position(x)
+		if a.x != x {
+			a.v = 0 // Hit position stop.
+		}
+	})
+	return a.x
+}
+
+// Position returns the actuator position. It implements [Actuator].
+func (a *SecondOrderActuator) Position() float64 { return a.x }
+
+// Rate returns the actuator rate [s^-1].
+func (a *SecondOrderActuator) Rate() float64 { return a.v }
+
+// advance integrates from *tLast to t in steps no larger than period.
+func advance(tLast *float64, started *bool, t, period float64, step func(h float64)) {
+	if !(period > 0) {
+		panic("actuator period must be positive")
+	}
+	if !*started {
+		*tLast, *started = t, true
+		return
+	}
+	for *tLast < t {
+		h := math.Min(period, t-*tLast)
+		if h < 1e-12 {
+			break
+		}
+		step(h)
+		*tLast += h
+	}
+	*tLast = math.Max(*tLast, t)
+}
@@ -0,0 +1,84 @@
+package control
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// AccelerationAutopilot converts commanded lateral accelerations in body frame into fin deflections.
+// The pitch channel controls acceleration along the body Z axis and the yaw channel along the body
+// Y axis. Each channel adds a feed-forward deflection of command/Effectiveness to a PID loop on the
+// acceleration error. Deflections are positive when they produce positive acceleration along their axis.
+type AccelerationAutopilot struct {
+	Pitch, Yaw PID
+	// Roll loop on roll rate error [rad/s]. Its output is the differential roll deflection.
+	Roll PID
+	// Effectiveness is the lateral acceleration per radian of channel deflection [m.s^-2.rad^-1]
+	// used for feed-forward. It usually scales with dynamic pressure. Zero disables feed-forward.
+	Effectiveness float64
+	// MaxDeflection limits the channel deflections [rad]. Zero disables the limit.
+	MaxDeflection float64
+	Sampler       Sampler
+
+	pitch, yaw, roll float64
+}
+
+// NewAccelerationAutopilot returns an autopilot sampled every period seconds with proportional and
+// integral acceleration gains kp [rad.s^2.m^-1] and ki [rad.s.m^-1] on pitch and yaw and no roll control.
+func NewAccelerationAutopilot(kp, ki, effectiveness, maxDeflection, period float64) *AccelerationAutopilot {
+	channel := PID{Kp: kp, Ki: ki, Sampler: Sampler{Period: period}}
+	return &AccelerationAutopilot{
+		Pitch:         channel,
+		Yaw:           channel,
+		Roll:          PID{Sampler: Sampler{Period: period}},
+		Effectiveness: effectiveness,
+		MaxDeflection: maxDeflection,
+		Sampler:       Sampler{Period: period},
+	}
+}
+
+// Update samples the commanded and measured body frame accelerations [m.s^-2] and roll rate
+// [rad/s] at time t and returns the deflections of four fins in cruciform arrangement. See
+// [FinDeflections]. Between samples the last deflections are held.
+func (ap *AccelerationAutopilot) Update(t float64, cmdB, measB md3.Vec, cmdRollRate, measRollRate float64) (fins [4]float64) {
+	if ap.Sampler.Due(t) {
+		var ffPitch, ffYaw float64
+		if ap.Effectiveness != 0 {
+			ffPitch, ffYaw = cmdB.Z/ap.Effectiveness, cmdB.Y/ap.Effectiveness
+		}
+		ap.pitch = ap.limit(ffPitch + ap.Pitch.Update(t, cmdB.Z-measB.Z))
+		ap.yaw = ap.limit(ffYaw + ap.Yaw.Update(t, cmdB.Y-measB.Y))
+		ap.roll = ap.limit(ap.Roll.Update(t, cmdRollRate-measRollRate))
+	}
+	return FinDeflections(ap.pitch, ap.yaw, ap.roll)
+}
+
+// Channels returns the last pitch, yaw and roll channel deflections [rad].
+func (ap *AccelerationAutopilot) Channels() (pitch, yaw, roll float64) {
+	return ap.pitch, ap.yaw, ap.roll
+}
+
+func (ap *AccelerationAutopilot) limit(d float64) float64 {
+	if ap.MaxDeflection > 0 {
+		return math.Max(-ap.MaxDeflection, math.Min(d, ap.MaxDeflection))
+	}
+	return d
+}
+
+// FinDeflections mixes pitch, yaw and roll channel deflections into the deflections of four fins
+// in cruciform (+) arrangement numbered clockwise looking forward from the top fin. Fins 1 and 3
+// (top and bottom) produce yaw, fins 2 and 4 (right and left) produce pitch and all fins
+// deflect differentially to produce roll.
+func FinDeflections(pitch, yaw, roll float64) [4]float64 {
+	return [4]float64{yaw + roll, pitch + roll, yaw - roll, pitch - roll}
+}
+
+// ChannelDeflections is the inverse of [FinDeflections]. It returns the pitch, yaw and roll
+// channel deflections from the deflections of four fins in cruciform arrangement.
+func ChannelDeflections(fins [4]float64) (pitch, yaw, roll float64) {
+	pitch = (fins[1] + fins[3]) / 2
+	yaw = (fins[0] + fins[2]) / 2
+	roll = (fins[0] - fins[2] + fins[1] - fins[3]) / 4
+	return pitch, yaw, roll
+}
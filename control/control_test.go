@@ -0,0 +1,182 @@
+package control
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+func TestSampler(t *testing.T) {
+	s := Sampler{Period: 0.01}
+	n := 0
+	for i := 0; i < 1000; i++ {
+		if s.Due(float64(i) * 0.001) {
+			n++
+		}
+	}
+	if n != 100 {
+		t.Errorf("want 100 samples in 1s at 100Hz, got %d", n)
+	}
+	// Physics step larger than sample period skips samples.
+	s = Sampler{Period: 0.01}
+	n = 0
+	for i := 0; i < 10; i++ {
+		if s.Due(float64(i) * 0.1) {
+			n++
+		}
+	}
+	if n != 10 {
+		t.Errorf("want sample every step, got %d", n)
+	}
+}
+
+func TestPID(t *testing.T) {
+	pid := NewPID(2, 10, 0, 0.1)
+	if u := pid.Update(0, 1); !md1.EqualWithinAbs(u, 2+10*0.1, 1e-12) {
+		t.Errorf("first sample want %g, got %g", 3., u)
+	}
+	// Output held between samples.
+	if u := pid.Update(0.05, 100); !md1.EqualWithinAbs(u, 3, 1e-12) {
+		t.Errorf("want held output 3, got %g", u)
+	}
+	if u := pid.Update(0.1, 1); !md1.EqualWithinAbs(u, 2+10*0.2, 1e-12) {
+		t.Errorf("second sample want %g, got %g", 4., u)
+	}
+
+	// Anti-windup: saturated integrator does not keep integrating.
+	pid = NewPID(1, 1, 0, 0.1)
+	pid.Min, pid.Max = -1, 1
+	for i := 0; i < 100; i++ {
+		pid.Update(float64(i)*0.1, 10)
+	}
+	if u := pid.Update(10, -0.5); u >= 1 {
+		t.Errorf("integrator wound up, output %g", u)
+	}
+
+	// Derivative on a ramp with filter reaches Kd times slope.
+	pid = NewPID(0, 0, 2, 0.01)
+	pid.Tf = 0.05
+	var u float64
+	for i := 0; i < 200; i++ {
+		tt := float64(i) * 0.01
+		u = pid.Update(tt, 3*tt)
+	}
+	if !md1.EqualWithinAbs(u, 6, 1e-6) {
+		t.Errorf("derivative of ramp want 6, got %g", u)
+	}
+}
+
+func TestLeadLag(t *testing.T) {
+	const k, zero, pole, period = 2., 5., 50., 0.001
+	ll := NewLeadLag(k, zero, pole, period)
+	var y float64
+	for i := 0; i < 100; i++ {
+		y = ll.Update(float64(i)*period, 3)
+	}
+	if !md1.EqualWithinAbs(y, k*3, 1e-9) {
+		t.Errorf("DC gain want %g, got %g", k*3, y)
+	}
+	// Tustin maps the Nyquist frequency to infinite frequency where gain is k*pole/zero.
+	ll = NewLeadLag(k, zero, pole, period)
+	for i := 0; i < 10000; i++ {
+		in := 1.
+		if i%2 == 1 {
+			in = -1
+		}
+		y = ll.Update(float64(i)*period, in)
+	}
+	if !md1.EqualWithinAbs(math.Abs(y), k*pole/zero, 1e-6) {
+		t.Errorf("high frequency gain want %g, got %g", k*pole/zero, math.Abs(y))
+	}
+}
+
+func TestActuators(t *testing.T) {
+	const dt = 0.001
+	limits := Limits{MaxPosition: 0.3, MaxRate: 2}
+	first := NewFirstOrderActuator(0.02, 1e-4, limits)
+	second := NewSecondOrderActuator(60, 0.5, 1e-4, Limits{MaxPosition: 0.3})
+	var prev, peak float64
+	for i := 0; i <= 1000; i++ {
+		tt := float64(i) * dt
+		x := first.Update(tt, 1) // Command beyond position limit.
+		if rate := (x - prev) / dt; rate > limits.MaxRate*(1+1e-9) {
+			t.Fatalf("rate limit exceeded at t=%g: %g", tt, rate)
+		}
+		prev = x
+		peak = math.Max(peak, second.Update(tt, 0.2))
+	}
+	if !md1.EqualWithinAbs(first.Position(), limits.MaxPosition, 1e-9) {
+		t.Errorf("want actuator at position limit %g, got %g", limits.MaxPosition, first.Position())
+	}
+	// Rate limited travel of 0.3 at 2/s takes 0.15s.
+	first = NewFirstOrderActuator(0.001, 1e-4, limits)
+	first.Update(0, 0)
+	if x := first.Update(0.1, 1); !md1.EqualWithinAbs(x, 0.2, 1e-9) {
+		t.Errorf("want rate limited position 0.2, got %g", x)
+	}
+	// Second order step response overshoot for damping 0.5 is exp(-pi*z/sqrt(1-z^2)) = 16.3%.
+	wantPeak := 0.2 * (1 + math.Exp(-math.Pi*0.5/math.Sqrt(0.75)))
+	if !md1.EqualWithinAbs(peak, wantPeak, 0.002) {
+		t.Errorf("want second order peak %g, got %g", wantPeak, peak)
+	}
+	if !md1.EqualWithinAbs(second.Position(), 0.2, 1e-6) {
+		t.Errorf("want second order settled at 0.2, got %g", second.Position())
+	}
+}
+
+func TestFinMixing(t *testing.T) {
+	fins := FinDeflections(0.1, -0.05, 0.02)
+	p, y, r := ChannelDeflections(fins)
+	if !md1.EqualWithinAbs(p, 0.1, 1e-15) || !md1.EqualWithinAbs(y, -0.05, 1e-15) || !md1.EqualWithinAbs(r, 0.02, 1e-15) {
+		t.Errorf("mixing not invertible: got (%g,%g,%g)", p, y, r)
+	}
+}
+
+func TestAutopilotClosedLoop(t *testing.T) {
+	// Body lateral acceleration is produced by fins through second order actuators. The autopilot
+	// runs at 200Hz, actuators at 5kHz and physics at 1kHz. Body axes are aligned with inertial axes.
+	const (
+		effectiveness = 400. // [m.s^-2.rad^-1]
+		dtPhysics     = 1e-3
+	)
+	ap := NewAccelerationAutopilot(0.001, 0.01, effectiveness, 20*math.Pi/180, 1./200)
+	var fins [4]Actuator
+	for i := range fins {
+		fins[i] = NewSecondOrderActuator(150, 0.7, 2e-4, Limits{MaxPosition: 20 * math.Pi / 180, MaxRate: 5})
+	}
+	var aB md3.Vec // Lateral acceleration produced by fins. Constant during a physics step.
+	earth := gnco.NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	phys := gnco.NewPhysicsPointIntegrator(&coords, 0, md3.Vec{X: earth.SemiMajorAxis + 1000e3}, md3.Vec{X: 300})
+	phys.RemoveForceModel("gravity")
+	phys.AddForceModel("fins", gnco.ForceFunc(func(s *gnco.ForceState) md3.Vec { return aB }))
+	cmd := md3.Vec{Y: -30, Z: 50}
+	var VBI0 md3.Vec
+	const tEnd = 2.
+	for i := 0; i < int(tEnd/dtPhysics); i++ {
+		tt := float64(i) * dtPhysics
+		cmdFins := ap.Update(tt, cmd, aB, 0, 0)
+		var actual [4]float64
+		for j := range fins {
+			actual[j] = fins[j].Update(tt, cmdFins[j])
+		}
+		pitch, yaw, _ := ChannelDeflections(actual)
+		aB = md3.Vec{Y: effectiveness * yaw, Z: effectiveness * pitch}
+		if tt >= tEnd-0.1 && VBI0 == (md3.Vec{}) {
+			_, _, VBI0 = phys.State()
+		}
+		phys.Step(dtPhysics, md3.Vec{})
+	}
+	if !md3.EqualElem(aB, cmd, 0.01*md3.Norm(cmd)) {
+		t.Errorf("autopilot did not track command %v, got %v", cmd, aB)
+	}
+	// Integrated velocity change over the last 0.1s matches the commanded acceleration.
+	_, _, VBI := phys.State()
+	dv := md3.Sub(VBI, VBI0)
+	if !md3.EqualElem(dv, md3.Scale(0.1, cmd), 0.01*md3.Norm(cmd)*0.1) {
+		t.Errorf("want velocity change %v, got %v", md3.Scale(0.1, cmd), dv)
+	}
+}
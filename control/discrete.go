@@ -0,0 +1,150 @@
+// Package control implements discrete controllers, actuator dynamics and autopilots for closed-loop
+// simulation. Blocks run at their own sample rates independent of the step of the physics integrator:
+// they are updated with the current simulation time at every physics step and hold their output
+// between samples (zero-order hold).
+package control
+
+import "math"
+
+// Sampler schedules a discrete block at a fixed sample period. The first sample is
+// taken on the first call to Due.
+type Sampler struct {
+	Period  float64 // Sample period [s].
+	next    float64
+	started bool
+}
+
+// Due reports whether a sample is due at time t and if so advances the schedule to the next
+// sample after t. Samples missed due to a physics step larger than the period are skipped.
+func (s *Sampler) Due(t float64) bool {
+	if !(s.Period > 0) {
+		panic("sampler period must be positive")
+	}
+	if !s.started {
+		s.next, s.started = t, true
+	}
+	// Tolerate round-off in accumulated simulation time.
+	if t < s.next-1e-9*s.Period {
+		return false
+	}
+	s.next += s.Period * (math.Floor((t-s.next)/s.Period+1e-9) + 1)
+	return true
+}
+
+// Reset restarts the schedule so the next call to Due takes a sample.
+func (s *Sampler) Reset() { s.started = false }
+
+// PID is a discrete proportional-integral-derivative controller with a filtered derivative
+// and integrator anti-windup when the output is limited.
+type PID struct {
+	Kp, Ki, Kd float64
+	// Tf is the time constant of the first order derivative filter [s]. Zero disables the filter.
+	Tf float64
+	// Output limits. Limits are disabled when Min >= Max.
+	Min, Max float64
+	Sampler  Sampler
+
+	integral, deriv, prevErr, output float64
+	primed                           bool
+}
+
+// NewPID returns a PID controller sampled every period seconds with no output limits.
+func NewPID(kp, ki, kd, period float64) *PID {
+	return &PID{Kp: kp, Ki: ki, Kd: kd, Sampler: Sampler{Period: period}}
+}
+
+// Update samples the error e = setpoint - measurement at time t and returns the controller output.
+// Between samples the last output is held.
+func (c *PID) Update(t, e float64) float64 {
+	if !c.Sampler.Due(t) {
+		return c.output
+	}
+	h := c.Sampler.Period
+	if !c.primed {
+		c.prevErr, c.primed = e, true // Avoid derivative kick on first sample.
+	}
+	de := e - c.prevErr
+	c.prevErr = e
+	if c.Tf > 0 {
+		c.deriv = (c.Tf*c.deriv + c.Kd*de) / (c.Tf + h)
+	} else {
+		c.deriv = c.Kd * de / h
+	}
+	integral := c.integral + c.Ki*h*e
+	u := c.Kp*e + integral + c.deriv
+	limited := c.Min < c.Max
+	switch {
+	case limited && u > c.Max:
+		u = c.Max
+		if e < 0 {
+			c.integral = integral // Integrating reduces saturation.
+		}
+	case limited && u < c.Min:
+		u = c.Min
+		if e > 0 {
+			c.integral = integral
+		}
+	default:
+		c.integral = integral
+	}
+	c.output = u
+	return u
+}
+
+// Output returns the last output of the controller.
+func (c *PID) Output() float64 { return c.output }
+
+// Reset clears the controller state.
+func (c *PID) Reset() {
+	c.integral, c.deriv, c.prevErr, c.output, c.primed = 0, 0, 0, 0, false
+	c.Sampler.Reset()
+}
+
+// LeadLag is a discrete lead-lag compensator with continuous transfer function
+//
+//	C(s) = K * (1 + s/Zero) / (1 + s/Pole)
+//
+// discretized with the bilinear (Tustin) transform. It is a lead compensator
+// when Pole > Zero and a lag compensator otherwise.
+type LeadLag struct {
+	Sampler Sampler
+
+	b0, b1, a1     float64
+	prevIn, output float64
+	primed         bool
+}
+
+// NewLeadLag returns a lead-lag compensator of gain k with zero and pole frequencies [rad/s] sampled
+// every period seconds. Frequencies must be positive.
+func NewLeadLag(k, zero, pole, period float64) *LeadLag {
+	if !(zero > 0) || !(pole > 0) || !(period > 0) {
+		panic("lead-lag requires positive zero, pole and period")
+	}
+	a := 2 / period
+	a0 := 1 + a/pole
+	return &LeadLag{
+		Sampler: Sampler{Period: period},
+		b0:      k * (1 + a/zero) / a0,
+		b1:      k * (1 - a/zero) / a0,
+		a1:      (1 - a/pole) / a0,
+	}
+}
+
+// Update samples input in at time t and returns the compensator output. The compensator starts
+// in steady state with its first input. Between samples the last output is held.
+func (ll *LeadLag) Update(t, in float64) float64 {
+	if !ll.Sampler.Due(t) {
+		return ll.output
+	}
+	if !ll.primed {
+		// Steady state y = K*x.
+		ll.prevIn, ll.output, ll.primed = in, in*(ll.b0+ll.b1)/(1+ll.a1), true
+		return ll.output
+	}
+	ll.output = ll.b0*in + ll.b1*ll.prevIn - ll.a1*ll.output
+	ll.prevIn = in
+	return ll.output
+}
+
+// Output returns the last output of the compensator.
+func (ll *LeadLag) Output() float64 { return ll.output }
@@ -9,7 +9,7 @@ import (
 type Coordinates interface {
 	AGravG() md3.Vec
 	TGE() md3.Mat3
-	SetFromEarthFixedCoords(SBIE md3.Vec, epochTime float64)
+	SetFromEarthFixedCoords(SBIE md3.Vec)
 	World() *World
 }
 
@@ -54,14 +54,14 @@ func (g GeocentricCoords) InertialCoords(epochTime float64) (sBII md3.Vec, TGI m
 	TEI := g.w.TEI(epochTime)
 	TGE := g.TGE()
 	TGI = md3.MulMat3(TGE, TEI)
-	sBIE := g.EarthFixedCoords(epochTime)
+	sBIE := g.EarthFixedCoords()
 	sBII = md3.MulMatVecTrans(TEI, sBIE)
 	return sBII, TGI
 }
 
 // EarthFixedCoords returns the planet-centerd, planet-fixed (ECEF) frame of reference coordinates. These rotate with the planet. See Earth-centered, earth fixed.
-func (g GeocentricCoords) EarthFixedCoords(epochTime float64) (sBIE md3.Vec) {
-	slon, clon := math.Sincos(g.Long + g.w.Rotation*epochTime)
+func (g GeocentricCoords) EarthFixedCoords() (sBIE md3.Vec) {
+	slon, clon := math.Sincos(g.Long)
 	slat, clat := math.Sincos(g.Lat)
 	sBIE.X = clat * clon
 	sBIE.Y = clat * slon
@@ -70,11 +70,11 @@ func (g GeocentricCoords) EarthFixedCoords(epochTime float64) (sBIE md3.Vec) {
 	return md3.Scale(radius, sBIE)
 }
 
-func (g *GeocentricCoords) SetFromEarthFixedCoords(sBIE md3.Vec, epochTime float64) {
+func (g *GeocentricCoords) SetFromEarthFixedCoords(sBIE md3.Vec) {
 	if g.w == nil {
 		panic("nil world")
 	}
-	*g = g.w.GeocentricFromEarthFixedCoords(sBIE, epochTime)
+	*g = g.w.GeocentricFromEarthFixedCoords(sBIE)
 }
 
 func (g GeocentricCoords) World() *World { return g.w }
@@ -100,6 +100,46 @@ func (g GeocentricCoords) Radius() float64 {
 	return g.w.Radius + g.Elev
 }
 
+// TopocentricVector returns the vector from g to target in g's geographic (North-East-Down) frame [m].
+func (g GeocentricCoords) TopocentricVector(target GeocentricCoords) (sTGG md3.Vec) {
+	if g.w == nil {
+		panic("nil world")
+	} else if target.w != g.w {
+		panic("coordinates of different worlds")
+	}
+	sTGE := md3.Sub(target.EarthFixedCoords(), g.EarthFixedCoords())
+	return md3.MulMatVec(g.TGE(), sTGE)
+}
+
+// LookAngles returns the topocentric azimuth [rad], elevation [rad] and slant range [m]
+// of target as seen from an observer at g. Azimuth is measured clockwise from North
+// in range [0, 2pi) and elevation is positive above the observer's horizon.
+// These are the angles a radar or optical observer at g would point at to see target.
+func (g GeocentricCoords) LookAngles(target GeocentricCoords) (azimuth, elevation, slantRange float64) {
+	elevation, azimuth, slantRange = ElevationAndBearingFromGeographicVector(g.TopocentricVector(target))
+	return azimuth, elevation, slantRange
+}
+
+// LookAnglesInertial returns the topocentric azimuth [rad], elevation [rad] and slant range [m]
+// of a body at inertial position SBI at epoch time t as seen from an observer at g.
+// See [GeocentricCoords.LookAngles].
+func (g GeocentricCoords) LookAnglesInertial(t float64, SBI md3.Vec) (azimuth, elevation, slantRange float64) {
+	sTGE := md3.Sub(md3.MulMatVec(g.w.TEI(t), SBI), g.EarthFixedCoords())
+	elevation, azimuth, slantRange = ElevationAndBearingFromGeographicVector(md3.MulMatVec(g.TGE(), sTGE))
+	return azimuth, elevation, slantRange
+}
+
+// FromLookAngles is the inverse of [GeocentricCoords.LookAngles]. It returns the coordinates
+// of the point seen from g at the given azimuth [rad], elevation [rad] and slant range [m].
+func (g GeocentricCoords) FromLookAngles(azimuth, elevation, slantRange float64) GeocentricCoords {
+	if g.w == nil {
+		panic("nil world")
+	}
+	sTGG := GeographicVectorFromElevationAndBearing(elevation, azimuth, slantRange)
+	sTGE := md3.Add(g.EarthFixedCoords(), md3.MulMatVecTrans(g.TGE(), sTGG))
+	return g.w.GeocentricFromEarthFixedCoords(sTGE)
+}
+
 // AGravG returns gravity acceleration in geographic coordinates. [m.s^-2]
 func (g GeocentricCoords) AGravG() (gravityVec md3.Vec) {
 	dbi := g.Radius()
@@ -107,23 +147,25 @@ func (g GeocentricCoords) AGravG() (gravityVec md3.Vec) {
 	return gravityVec
 }
 
+// AGravG returns gravity acceleration in geographic coordinates including
+// the second degree zonal harmonic (J2) term. [m.s^-2]
 func (g GeodesicCoords) AGravG() (gravityVec md3.Vec) {
-	// Sqrt(0.5)
-	const sqrtHalf = 0.7071067811865475244008443621048490392848359376884740365883398689
-	const dum2 = 3 * sqrtHalf
+	// Normalized C20 relates to J2 by J2 = -sqrt(5)*C20.
+	const sqrt5 = 2.236067977499789696409173668731276235440618359611525724270897245
+	const dum2 = 3 * sqrt5
 	w := g.c.w
 	dbi := g.c.Radius()
 	dum1 := w.G() / (dbi * dbi)
 	dum3 := w.SemiMajorAxis / dbi
 	dum3 *= dum3 // square it, much faster than Pow
 	sinlat, coslat := math.Sincos(g.c.Lat)
-	gravityVec.X = -dum1 * dum2 * w.C20 * dum3 * sinlat * coslat
+	gravityVec.X = dum1 * dum2 * w.C20 * dum3 * sinlat * coslat
 	gravityVec.Z = dum1 * (1 + dum2/2*w.C20*dum3*(3*sinlat*sinlat-1))
 	return gravityVec
 }
 
-func (g *GeodesicCoords) SetFromEarthFixedCoords(sBIE md3.Vec, epochTime float64) {
-	g.c.SetFromEarthFixedCoords(sBIE, epochTime)
+func (g *GeodesicCoords) SetFromEarthFixedCoords(sBIE md3.Vec) {
+	g.c.SetFromEarthFixedCoords(sBIE)
 }
 
 func (g GeodesicCoords) World() *World { return g.c.w }
@@ -0,0 +1,169 @@
+package gnco
+
+import (
+	"math"
+	"slices"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Atmosphere models the density of a world's atmosphere.
+type Atmosphere interface {
+	// Density returns the atmospheric density [kg.m^-3] at the body state s.
+	Density(s *ForceState) float64
+}
+
+var (
+	_ Atmosphere = StandardAtmosphere{}
+	_ Atmosphere = ExponentialAtmosphere{}
+	_ Atmosphere = (*HarrisPriester)(nil)
+	_ ForceModel = (*Drag)(nil)
+)
+
+// Drag is the aerodynamic drag force model of a body moving through an atmosphere that
+// co-rotates with the world. Drag depends on velocity so integrators using it should
+// be set to [MethodGRKN54] with [PhysicsPointIntegrator.SetMethod].
+type Drag struct {
+	// BallisticCoefficient is the ratio of mass to drag area m/(Cd*A) [kg.m^-2].
+	BallisticCoefficient float64
+	Atmosphere           Atmosphere
+}
+
+// NewDrag returns a drag force model for a body of given mass [kg], drag coefficient [Adim]
+// and cross-sectional area [m^2] moving through atmos.
+func NewDrag(atmos Atmosphere, mass, cd, area float64) *Drag {
+	return &Drag{
+		BallisticCoefficient: mass / (cd * area),
+		Atmosphere:           atmos,
+	}
+}
+
+// Accel returns the drag acceleration in inertial frame [m.s^-2]. It implements [ForceModel].
+func (d *Drag) Accel(s *ForceState) md3.Vec {
+	rho := d.Atmosphere.Density(s)
+	if rho == 0 {
+		return md3.Vec{}
+	}
+	vrel := RelativeVelocity(s)
+	return md3.Scale(-0.5*rho*md3.Norm(vrel)/d.BallisticCoefficient, vrel)
+}
+
+// RelativeVelocity returns the velocity of the body relative to an atmosphere co-rotating with
+// the world expressed in inertial frame [m/s]. It is given by VBI - ω×SBI where ω is the world's
+// angular velocity about the inertial Z axis.
+func RelativeVelocity(s *ForceState) md3.Vec {
+	omega := s.Coords.World().Rotation
+	return md3.Vec{
+		X: s.VBI.X + omega*s.SBI.Y,
+		Y: s.VBI.Y - omega*s.SBI.X,
+		Z: s.VBI.Z,
+	}
+}
+
+// StandardAtmosphere is the [InternationalStandardAtmosphere] density model. It is
+// only meaningful below 80km, above which a near vacuum density is returned.
+type StandardAtmosphere struct {
+	// SeaLevelTemperature [K]. If zero the standard 288.15K is used.
+	SeaLevelTemperature float64
+}
+
+// Density returns the standard atmosphere density at the body's height above the reference ellipsoid.
+func (sa StandardAtmosphere) Density(s *ForceState) float64 {
+	T0 := sa.SeaLevelTemperature
+	if T0 == 0 {
+		T0 = 288.15
+	}
+	_, _, rho := InternationalStandardAtmosphere(ellipsoidalHeight(s), T0)
+	return rho
+}
+
+// ExponentialAtmosphere models density as decaying exponentially with height above a base altitude:
+//
+//	rho = BaseDensity * exp(-(h-BaseAltitude)/ScaleHeight)
+//
+// It is accurate over a few scale heights around the base altitude.
+type ExponentialAtmosphere struct {
+	BaseAltitude float64 // Height above reference ellipsoid of base density [m].
+	BaseDensity  float64 // Density at base altitude [kg.m^-3].
+	ScaleHeight  float64 // Height over which density decreases by a factor e [m].
+}
+
+// Density returns the exponential atmosphere density at the body's height above the reference ellipsoid.
+func (ea ExponentialAtmosphere) Density(s *ForceState) float64 {
+	return ea.BaseDensity * math.Exp(-(ellipsoidalHeight(s)-ea.BaseAltitude)/ea.ScaleHeight)
+}
+
+// HarrisPriester is the modified Harris-Priester model of Earth's upper atmosphere valid between
+// 100km and 1000km for mean solar activity. It accounts for the diurnal density bulge which lags
+// the sub-solar point by 30 degrees. Density is zero outside the model's height range.
+// See Montenbruck, Gill - Satellite Orbits (3.5.1).
+type HarrisPriester struct {
+	// Exponent controls the width of the diurnal bulge. Use 2 for low inclination
+	// orbits and up to 6 for polar orbits.
+	Exponent float64
+	sun      *ThirdBody
+}
+
+// NewHarrisPriester returns the Harris-Priester atmosphere for an integrator whose epoch time zero
+// corresponds to the Julian date epochJD. The bulge exponent is initialized to 4.
+func NewHarrisPriester(w *World, epochJD float64) *HarrisPriester {
+	return &HarrisPriester{Exponent: 4, sun: NewSunPerturbation(w, epochJD)}
+}
+
+// Density returns the Harris-Priester density at the body's height above the reference ellipsoid.
+func (hp *HarrisPriester) Density(s *ForceState) float64 {
+	h := ellipsoidalHeight(s)
+	n := len(hpHeight)
+	if h < hpHeight[0] || h >= hpHeight[n-1] {
+		return 0
+	}
+	i, found := slices.BinarySearch(hpHeight[:], h)
+	if !found {
+		i--
+	}
+	// Exponential interpolation between table heights.
+	interp := func(rho *[len(hpHeight)]float64) float64 {
+		H := (hpHeight[i] - hpHeight[i+1]) / math.Log(rho[i+1]/rho[i])
+		return rho[i] * math.Exp((hpHeight[i]-h)/H)
+	}
+	rhoMin, rhoMax := interp(&hpMinDensity), interp(&hpMaxDensity)
+	// Apex of diurnal bulge is rotated from the Sun direction about the Z axis.
+	const lag = 30 * deg
+	uSun := md3.Unit(hp.sun.PositionInertial(s.T))
+	sinl, cosl := math.Sincos(lag)
+	apex := md3.Vec{X: cosl*uSun.X - sinl*uSun.Y, Y: sinl*uSun.X + cosl*uSun.Y, Z: uSun.Z}
+	cosPsi := md3.Dot(apex, md3.Unit(s.SBI))
+	cosPow := math.Pow(math.Max(0.5+0.5*cosPsi, 0), hp.Exponent/2)
+	return rhoMin + (rhoMax-rhoMin)*cosPow
+}
+
+func ellipsoidalHeight(s *ForceState) float64 {
+	w := s.Coords.World()
+	_, _, h := w.GeodeticFromEarthFixed(md3.MulMatVec(w.TEI(s.T), s.SBI))
+	return h
+}
+
+// Harris-Priester table for mean solar activity. Heights in [m], densities in [kg.m^-3].
+var (
+	hpHeight = [...]float64{
+		100e3, 120e3, 130e3, 140e3, 150e3, 160e3, 170e3, 180e3, 190e3, 200e3,
+		210e3, 220e3, 230e3, 240e3, 250e3, 260e3, 270e3, 280e3, 290e3, 300e3,
+		320e3, 340e3, 360e3, 380e3, 400e3, 420e3, 440e3, 460e3, 480e3, 500e3,
+		520e3, 540e3, 560e3, 580e3, 600e3, 620e3, 640e3, 660e3, 680e3, 700e3,
+		720e3, 740e3, 760e3, 780e3, 800e3, 840e3, 880e3, 920e3, 960e3, 1000e3,
+	}
+	hpMinDensity = [len(hpHeight)]float64{
+		4.974e-07, 2.490e-08, 8.377e-09, 3.899e-09, 2.122e-09, 1.263e-09, 8.008e-10, 5.283e-10, 3.617e-10, 2.557e-10,
+		1.839e-10, 1.341e-10, 9.949e-11, 7.488e-11, 5.709e-11, 4.403e-11, 3.430e-11, 2.697e-11, 2.139e-11, 1.708e-11,
+		1.099e-11, 7.214e-12, 4.824e-12, 3.274e-12, 2.249e-12, 1.558e-12, 1.091e-12, 7.701e-13, 5.474e-13, 3.916e-13,
+		2.819e-13, 2.042e-13, 1.488e-13, 1.092e-13, 8.070e-14, 6.012e-14, 4.519e-14, 3.430e-14, 2.632e-14, 2.043e-14,
+		1.607e-14, 1.281e-14, 1.036e-14, 8.496e-15, 7.069e-15, 4.680e-15, 3.200e-15, 2.210e-15, 1.560e-15, 1.150e-15,
+	}
+	hpMaxDensity = [len(hpHeight)]float64{
+		4.974e-07, 2.490e-08, 8.710e-09, 4.059e-09, 2.215e-09, 1.344e-09, 8.758e-10, 6.010e-10, 4.297e-10, 3.162e-10,
+		2.396e-10, 1.853e-10, 1.455e-10, 1.157e-10, 9.308e-11, 7.555e-11, 6.182e-11, 5.095e-11, 4.226e-11, 3.526e-11,
+		2.511e-11, 1.819e-11, 1.337e-11, 9.955e-12, 7.492e-12, 5.684e-12, 4.355e-12, 3.362e-12, 2.612e-12, 2.042e-12,
+		1.605e-12, 1.267e-12, 1.005e-12, 7.997e-13, 6.390e-13, 5.123e-13, 4.121e-13, 3.325e-13, 2.691e-13, 2.185e-13,
+		1.779e-13, 1.452e-13, 1.190e-13, 9.776e-14, 8.059e-14, 5.741e-14, 4.210e-14, 3.130e-14, 2.360e-14, 1.810e-14,
+	}
+)
@@ -0,0 +1,115 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestDragCoRotating(t *testing.T) {
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	SBI := md3.Vec{X: 4000e3, Y: 5000e3, Z: 1000e3}
+	// A body at rest with respect to the world moves with the atmosphere.
+	VBI := md3.Cross(md3.Vec{Z: earth.Rotation}, SBI)
+	s := ForceState{SBI: SBI, VBI: VBI, Coords: &coords}
+	if vrel := RelativeVelocity(&s); md3.Norm(vrel) > 1e-9 {
+		t.Errorf("want zero relative velocity for co-rotating body, got %v", vrel)
+	}
+	drag := NewDrag(ExponentialAtmosphere{BaseDensity: 1e-9, ScaleHeight: 60e3}, 100, 2, 1)
+	if a := drag.Accel(&s); md3.Norm(a) > 1e-15 {
+		t.Errorf("want no drag for co-rotating body, got %v", a)
+	}
+	// Drag opposes relative velocity and scales with its square.
+	s.VBI = md3.Add(VBI, md3.Vec{Z: 7000})
+	a := drag.Accel(&s)
+	rho := drag.Atmosphere.Density(&s)
+	want := 0.5 * rho * 7000 * 7000 / drag.BallisticCoefficient
+	if !md1.EqualWithinAbs(a.Z, -want, want*1e-6) || math.Hypot(a.X, a.Y) > want*1e-6 {
+		t.Errorf("want drag (0,0,%g), got %v", -want, a)
+	}
+}
+
+func TestAtmosphereDensity(t *testing.T) {
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	// Body above the equator on the inertial X axis at given height.
+	state := func(h float64) *ForceState {
+		return &ForceState{SBI: md3.Vec{X: earth.SemiMajorAxis + h}, Coords: &coords}
+	}
+	if rho := (StandardAtmosphere{}).Density(state(0)); !md1.EqualWithinAbs(rho, 1.225, 1e-3) {
+		t.Errorf("standard atmosphere sea level density want 1.225, got %g", rho)
+	}
+	exp := ExponentialAtmosphere{BaseAltitude: 300e3, BaseDensity: 2.418e-11, ScaleHeight: 53.628e3}
+	if rho := exp.Density(state(300e3 + exp.ScaleHeight)); !md1.EqualWithinAbs(rho, exp.BaseDensity/math.E, 1e-15) {
+		t.Errorf("exponential density one scale height above base want %g, got %g", exp.BaseDensity/math.E, rho)
+	}
+
+	hp := NewHarrisPriester(earth, JulianDateJ2000)
+	if rho := hp.Density(state(50e3)); rho != 0 {
+		t.Errorf("Harris-Priester below model range want 0, got %g", rho)
+	}
+	if rho := hp.Density(state(1200e3)); rho != 0 {
+		t.Errorf("Harris-Priester above model range want 0, got %g", rho)
+	}
+	const h = 400e3
+	const rhoMin, rhoMax = 2.249e-12, 7.492e-12
+	sSun := hp.sun.PositionInertial(0)
+	sinl, cosl := math.Sincos(30 * deg)
+	apex := md3.Vec{X: cosl*sSun.X - sinl*sSun.Y, Y: sinl*sSun.X + cosl*sSun.Y, Z: sSun.Z}
+	r := earth.SemiMajorAxis + h
+	for _, test := range []struct {
+		name string
+		dir  md3.Vec
+		want float64
+	}{
+		{name: "bulge apex", dir: apex, want: rhoMax},
+		{name: "antipode", dir: md3.Scale(-1, apex), want: rhoMin},
+	} {
+		SBI := md3.Scale(r, md3.Unit(test.dir))
+		// Correct height for the flattening of the world.
+		_, _, hEllipsoid := earth.GeodeticFromEarthFixed(md3.MulMatVec(earth.TEI(0), SBI))
+		SBI = md3.Scale(r+h-hEllipsoid, md3.Unit(test.dir))
+		rho := hp.Density(&ForceState{SBI: SBI, Coords: &coords})
+		if !md1.EqualWithinAbs(rho, test.want, test.want*1e-6) {
+			t.Errorf("Harris-Priester %s: want %g, got %g", test.name, test.want, rho)
+		}
+	}
+}
+
+func TestDragEnergyLoss(t *testing.T) {
+	// Energy lost to drag must equal the work done by drag.
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	const r, dt = 6700e3, 5.
+	SBI0, VBI0 := md3.Vec{X: r}, md3.Vec{Y: math.Sqrt(earth.G() / r)}
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	phys.SetMethod(MethodGRKN54)
+	phys.AddForceModel("drag", NewDrag(ExponentialAtmosphere{BaseAltitude: 300e3, BaseDensity: 1e-9, ScaleHeight: 60e3}, 10, 2, 1))
+	energy := func(SBI, VBI md3.Vec) float64 {
+		return md3.Norm2(VBI)/2 - earth.G()/md3.Norm(SBI)
+	}
+	power := func(VBI md3.Vec) float64 {
+		return md3.Dot(phys.Forces(nil)[1].AccelInertial, VBI)
+	}
+	E0 := energy(SBI0, VBI0)
+	P0 := power(VBI0)
+	var work float64
+	for i := 0; i < 200; i++ {
+		_, SBI, VBI := phys.Step(dt, md3.Vec{})
+		P1 := power(VBI)
+		work += dt * (P0 + P1) / 2
+		P0 = P1
+		if i == 199 {
+			lost := energy(SBI, VBI) - E0
+			if lost >= 0 {
+				t.Fatalf("drag did not dissipate energy, got %g", lost)
+			}
+			if !md1.EqualWithinAbs(lost, work, math.Abs(work)*1e-3) {
+				t.Errorf("energy change %g does not match drag work %g", lost, work)
+			}
+		}
+	}
+}
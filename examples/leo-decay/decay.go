@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/orbits"
+)
+
+func main() {
+	err := run()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	earth := gnco.NewEarth()
+	const (
+		t0          = 0.0                    // [s] epoch time
+		epochJD     = gnco.JulianDateJ2000   // Julian date of epoch time zero.
+		altitude    = 250e3                  // [m] initial orbit altitude
+		inclination = 51.6 * math.Pi / 180   // [rad]
+		reentry     = 120e3                  // [m] altitude at which object is considered to have re-entered
+		maxDuration = 60 * 24 * 60 * 60      // [s]
+		dt          = 10.                    // [s] integration step
+		mass        = 1.33                   // [kg] a 1U cubesat
+		cd          = 2.2                    // Drag coefficient [Adim]
+		area        = 0.01                   // [m^2] Cross-sectional area
+		printEvery  = 24 * 60 * 60 / int(dt) // Print once a day.
+	)
+	orbit, err := orbits.NewCircular(earth.SemiMajorAxis + altitude)
+	if err != nil {
+		return err
+	}
+	_, vt := orbit.Velocity(earth.G(), 0)
+	fmt.Printf("initial orbit period %.1f minutes\n", orbit.Period(earth.G())/60)
+	// Start at the ascending node. Velocity is tangential to the orbit and tilted by the inclination.
+	SBI0 := md3.Vec{X: orbit.Apoapsis()}
+	sini, cosi := math.Sincos(inclination)
+	VBI0 := md3.Vec{Y: vt * cosi, Z: vt * sini}
+
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	integrator := gnco.NewPhysicsPointIntegrator(&coords, t0, SBI0, VBI0)
+	// Drag depends on velocity so we use an integration method with exact stage velocities.
+	integrator.SetMethod(gnco.MethodGRKN54)
+	atmos := gnco.NewHarrisPriester(earth, epochJD)
+	atmos.Exponent = 6 // Highly inclined orbit.
+	integrator.AddForceModel("drag", gnco.NewDrag(atmos, mass, cd, area))
+
+	t, SBI, VBI := t0, SBI0, VBI0
+	h := altitude
+	for i := 1; h > reentry && t-t0 < maxDuration; i++ {
+		t, SBI, VBI = integrator.Step(dt, md3.Vec{})
+		_, _, h = earth.GeodeticFromEarthFixed(md3.MulMatVec(earth.TEI(t), SBI))
+		if i%printEvery == 0 {
+			// Osculating semi-major axis from vis-viva equation is less noisy than altitude
+			// which varies over an orbit due to the world's flattening.
+			r, v := md3.Norm(SBI), md3.Norm(VBI)
+			a := 1 / (2/r - v*v/earth.G())
+			fmt.Printf("day %3.0f: mean altitude %6.1fkm\n", (t-t0)/86400, (a-earth.SemiMajorAxis)/1e3)
+		}
+	}
+	if h > reentry {
+		fmt.Printf("object still in orbit after %.0f days at altitude %.1fkm\n", (t-t0)/86400, h/1e3)
+		return nil
+	}
+	fmt.Printf("re-entry after %.2f days\n", (t-t0)/86400)
+	return nil
+}
@@ -0,0 +1,181 @@
+package gnco
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// ForceState is the state of a point body at which a [ForceModel] is evaluated.
+type ForceState struct {
+	T   float64 // Epoch time [s].
+	SBI md3.Vec // Inertial position [m].
+	VBI md3.Vec // Inertial velocity [m/s].
+	// TGI is the transformation tensor of geographic wrt inertial coordinates.
+	// Accelerations in geographic frame are converted to inertial with md3.MulMatVecTrans(TGI, AG).
+	TGI md3.Mat3
+	// Coords are the coordinates of the body at SBI. They are only valid during the Accel call.
+	Coords Coordinates
+}
+
+// ForceModel computes an acceleration acting on a point body such as gravity, drag, thrust or
+// third body perturbations. Force models are registered on a [PhysicsPointIntegrator] and
+// evaluated at every integrator stage.
+type ForceModel interface {
+	// Accel returns the acceleration in inertial frame [m.s^-2] acting on the body in state s.
+	Accel(s *ForceState) md3.Vec
+}
+
+// ForceGradient is implemented by force models that provide the partial derivatives of their
+// acceleration used to propagate the state transition matrix. Force models that do not
+// implement it are differentiated numerically, see [PhysicsPointIntegrator.STM].
+type ForceGradient interface {
+	ForceModel
+	// Gradient returns the partial derivatives of the acceleration in inertial frame with respect to
+	// inertial position [s^-2] and inertial velocity [s^-1] at the body state s.
+	Gradient(s *ForceState) (dadr, dadv md3.Mat3)
+}
+
+var (
+	_ ForceGradient = Gravity{}
+	_ ForceModel    = Gravity{}
+	_ ForceModel    = ForceFunc(nil)
+	_ ForceModel    = (*ThirdBody)(nil)
+)
+
+// ForceFunc adapts an ordinary function to the [ForceModel] interface.
+type ForceFunc func(s *ForceState) md3.Vec
+
+// Accel returns f(s).
+func (f ForceFunc) Accel(s *ForceState) md3.Vec { return f(s) }
+
+// Gravity is the central body gravity force model given by the [Coordinates] AGravG method.
+type Gravity struct{}
+
+// Accel returns the gravity acceleration in inertial frame [m.s^-2].
+func (Gravity) Accel(s *ForceState) md3.Vec {
+	return md3.MulMatVecTrans(s.TGI, s.Coords.AGravG())
+}
+
+// Gradient returns the gravity gradient in inertial frame. It is exact for the point mass gravity of
+// [GeocentricCoords] and the J2 gravity of [GeodesicCoords]. Other coordinates are differentiated numerically.
+// It implements [ForceGradient].
+func (g Gravity) Gradient(s *ForceState) (dadr, dadv md3.Mat3) {
+	switch s.Coords.(type) {
+	case *GeocentricCoords:
+		return pointMassGradient(s.Coords.World().G(), s.SBI), md3.Mat3{}
+	case *GeodesicCoords:
+		w := s.Coords.World()
+		return md3.AddMat3(pointMassGradient(w.G(), s.SBI), j2Gradient(w, s.SBI)), md3.Mat3{}
+	}
+	return numericGradient(s, g, false)
+}
+
+// pointMassGradient returns the gradient of point mass gravity of parameter gm at r:
+//
+//	gm/|r|^3 * (3*r*rᵀ/|r|^2 - I)
+func pointMassGradient(gm float64, r md3.Vec) md3.Mat3 {
+	r2 := md3.Norm2(r)
+	k := gm / (r2 * math.Sqrt(r2))
+	return md3.ScaleMat3(md3.SubMat3(md3.ScaleMat3(md3.Prod(r, r), 3/r2), md3.IdentityMat3()), k)
+}
+
+// j2Gradient returns the gradient of the J2 gravity term of w at inertial position r. The
+// world's rotation axis coincides with the inertial Z axis so the zonal term is frame independent.
+func j2Gradient(w *World, r md3.Vec) md3.Mat3 {
+	// Acceleration is a_i = k*x_i*(c_i - 5z²/r²)/r^5 with c = (1, 1, 3).
+	const sqrt5 = 2.236067977499789696409173668731276235440618359611525724270897245
+	J2 := -sqrt5 * w.C20
+	k := -1.5 * J2 * w.G() * w.SemiMajorAxis * w.SemiMajorAxis
+	x := [3]float64{r.X, r.Y, r.Z}
+	c := [3]float64{1, 1, 3}
+	r2 := md3.Norm2(r)
+	z2 := r.Z * r.Z
+	r5 := r2 * r2 * math.Sqrt(r2)
+	r7 := r5 * r2
+	r9 := r7 * r2
+	var m [9]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			v := x[i] * x[j] * (35*z2/r9 - 5*c[i]/r7)
+			if i == j {
+				v += c[i]/r5 - 5*z2/r7
+			}
+			if j == 2 {
+				v -= 10 * x[i] * r.Z / r7
+			}
+			m[i*3+j] = k * v
+		}
+	}
+	return md3.NewMat3(m[:])
+}
+
+// numericGradient returns the partial derivatives of f's acceleration at the body state s
+// approximated by central differences. The velocity derivatives are only computed if withVelocity is true.
+func numericGradient(s *ForceState, f ForceModel, withVelocity bool) (dadr, dadv md3.Mat3) {
+	const hr, hv = 1.0, 1e-3 // Position [m] and velocity [m/s] perturbations.
+	coords := s.Coords
+	var cr, cv [3]md3.Vec
+	for j := 0; j < 3; j++ {
+		dr := md3.Vec{}
+		setAxis(&dr, j, hr)
+		sp := newForceState(coords, s.T, md3.Add(s.SBI, dr), s.VBI)
+		ap := f.Accel(&sp)
+		sm := newForceState(coords, s.T, md3.Sub(s.SBI, dr), s.VBI)
+		cr[j] = md3.Scale(1/(2*hr), md3.Sub(ap, f.Accel(&sm)))
+		if !withVelocity {
+			continue
+		}
+		dv := md3.Vec{}
+		setAxis(&dv, j, hv)
+		sp = newForceState(coords, s.T, s.SBI, md3.Add(s.VBI, dv))
+		ap = f.Accel(&sp)
+		sm = newForceState(coords, s.T, s.SBI, md3.Sub(s.VBI, dv))
+		cv[j] = md3.Scale(1/(2*hv), md3.Sub(ap, f.Accel(&sm)))
+	}
+	// Restore the coordinates of the unperturbed state.
+	*s = newForceState(coords, s.T, s.SBI, s.VBI)
+	return matFromCols(cr), matFromCols(cv)
+}
+
+func setAxis(v *md3.Vec, axis int, value float64) {
+	switch axis {
+	case 0:
+		v.X = value
+	case 1:
+		v.Y = value
+	default:
+		v.Z = value
+	}
+}
+
+func matFromCols(c [3]md3.Vec) md3.Mat3 {
+	return mat3(
+		c[0].X, c[1].X, c[2].X,
+		c[0].Y, c[1].Y, c[2].Y,
+		c[0].Z, c[1].Z, c[2].Z,
+	)
+}
+
+// newForceState sets coord from the inertial position and returns the state force models are evaluated at.
+func newForceState(coord Coordinates, t float64, SBII, VBII md3.Vec) ForceState {
+	TEI := coord.World().TEI(t)
+	SBIE := md3.MulMatVec(TEI, SBII)
+	coord.SetFromEarthFixedCoords(SBIE)
+	// Calculate TM geographic wrt earth coordinates.
+	TGE := coord.TGE()
+	return ForceState{
+		T:   t,
+		SBI: SBII,
+		VBI: VBII,
+		// Calculate TM of geographic wrt inertial coordinates.
+		TGI:    md3.MulMat3(TGE, TEI),
+		Coords: coord,
+	}
+}
+
+// ForceContribution is the acceleration contributed by a single named force model.
+type ForceContribution struct {
+	Name          string
+	AccelInertial md3.Vec // [m.s^-2]
+}
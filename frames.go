@@ -1,6 +1,10 @@
 package gnco
 
-import "github.com/soypat/geometry/md3"
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
 
 type Frame rune
 
@@ -90,3 +94,18 @@ func (F Frame) ToBody(v Orientation, frameVec md3.Vec) md3.Vec {
 	}
 	return frameVec
 }
+
+// TVGFromVelocity returns the rotation tensor of velocity wrt geographic coordinates for
+// a velocity VBG in geographic frame. The velocity frame's X axis is along the velocity,
+// its Y axis is horizontal to the right and its Z axis completes the right handed frame.
+// A vertical or zero velocity has an undefined heading which is taken as North.
+func TVGFromVelocity(VBG md3.Vec) md3.Mat3 {
+	flightPath, heading, _ := ElevationAndBearingFromGeographicVector(VBG)
+	sinp, cosp := math.Sincos(flightPath)
+	sinh, cosh := math.Sincos(heading)
+	return mat3(
+		cosp*cosh, cosp*sinh, -sinp,
+		-sinh, cosh, 0,
+		sinp*cosh, sinp*sinh, cosp,
+	)
+}
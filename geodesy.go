@@ -0,0 +1,174 @@
+package gnco
+
+import (
+	"errors"
+	"math"
+)
+
+var errVincentyNoConvergence = errors.New("vincenty inverse did not converge, points may be near antipodal")
+
+// GreatCircleInverse solves the inverse geodesic problem on the reference sphere of radius w.Radius
+// using the haversine formula. Latitudes and longitudes are geocentric [rad].
+// It returns the great-circle distance [m] between the two points along the reference sphere,
+// the initial bearing at point 1 and final bearing at point 2, both clockwise from North in range [0, 2pi).
+func (w *World) GreatCircleInverse(lat1, long1, lat2, long2 float64) (distance, bearing1, bearing2 float64) {
+	dlat := lat2 - lat1
+	dlong := long2 - long1
+	slat1, clat1 := math.Sincos(lat1)
+	slat2, clat2 := math.Sincos(lat2)
+	sdlong, cdlong := math.Sincos(dlong)
+	sdlat2 := math.Sin(dlat / 2)
+	sdlong2 := math.Sin(dlong / 2)
+	hav := sdlat2*sdlat2 + clat1*clat2*sdlong2*sdlong2
+	distance = 2 * w.Radius * math.Asin(math.Sqrt(math.Min(hav, 1)))
+	bearing1 = math.Atan2(sdlong*clat2, clat1*slat2-slat1*clat2*cdlong)
+	// Final bearing is the reversed initial bearing from point 2 to point 1.
+	bearing2 = math.Atan2(-sdlong*clat1, clat2*slat1-slat2*clat1*cdlong) + math.Pi
+	return distance, wrapBearing(bearing1), wrapBearing(bearing2)
+}
+
+// GreatCircleDirect solves the direct geodesic problem on the reference sphere of radius w.Radius.
+// Starting at geocentric lat1, long1 [rad] and travelling distance [m] with an initial bearing [rad]
+// it returns the arrival point and final bearing in range [0, 2pi).
+func (w *World) GreatCircleDirect(lat1, long1, bearing1, distance float64) (lat2, long2, bearing2 float64) {
+	delta := distance / w.Radius
+	slat1, clat1 := math.Sincos(lat1)
+	sd, cd := math.Sincos(delta)
+	sb, cb := math.Sincos(bearing1)
+	slat2 := slat1*cd + clat1*sd*cb
+	lat2 = math.Asin(slat2)
+	long2 = long1 + math.Atan2(sb*sd*clat1, cd-slat1*slat2)
+	_, _, bearing2 = w.GreatCircleInverse(lat1, long1, lat2, long2)
+	if distance == 0 {
+		bearing2 = wrapBearing(bearing1)
+	}
+	return lat2, clampLongLat(long2), bearing2
+}
+
+// GeodesicInverse solves the inverse geodesic problem on the reference ellipsoid defined
+// by w.SemiMajorAxis and w's flattening using Vincenty's formulae. Latitudes are geodetic and
+// longitudes are in radians. It returns the distance [m] along the ellipsoid surface, the initial bearing
+// at point 1 and final (forward) bearing at point 2, both clockwise from North in range [0, 2pi).
+//
+// Vincenty's method is accurate to within 0.5mm but may fail to converge for nearly antipodal points,
+// in which case an error is returned.
+func (w *World) GeodesicInverse(lat1, long1, lat2, long2 float64) (distance, bearing1, bearing2 float64, err error) {
+	const (
+		maxIter = 200
+		tol     = 1e-12
+	)
+	a, f := w.SemiMajorAxis, w.flattening
+	b := (1 - f) * a
+	L := long2 - long1
+	U1 := math.Atan((1 - f) * math.Tan(lat1))
+	U2 := math.Atan((1 - f) * math.Tan(lat2))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	lambda := L
+	var sinLambda, cosLambda, sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	converged := false
+	for i := 0; i < maxIter; i++ {
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, 0, 0, nil // Coincident points.
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0 // Equatorial line.
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		C := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = L + (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < tol {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return math.NaN(), math.NaN(), math.NaN(), errVincentyNoConvergence
+	}
+	u2 := cos2Alpha * (a*a - b*b) / (b * b)
+	A, B := vincentyAB(u2)
+	deltaSigma := vincentyDeltaSigma(B, sinSigma, cosSigma, cos2SigmaM)
+	distance = b * A * (sigma - deltaSigma)
+	bearing1 = math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+	bearing2 = math.Atan2(cosU1*sinLambda, -sinU1*cosU2+cosU1*sinU2*cosLambda)
+	return distance, wrapBearing(bearing1), wrapBearing(bearing2), nil
+}
+
+// GeodesicDirect solves the direct geodesic problem on the reference ellipsoid defined
+// by w.SemiMajorAxis and w's flattening using Vincenty's formulae. Starting at geodetic lat1, long1 [rad]
+// and travelling distance [m] along the ellipsoid with an initial bearing [rad] it returns
+// the arrival point and final (forward) bearing in range [0, 2pi).
+func (w *World) GeodesicDirect(lat1, long1, bearing1, distance float64) (lat2, long2, bearing2 float64) {
+	const (
+		maxIter = 200
+		tol     = 1e-12
+	)
+	a, f := w.SemiMajorAxis, w.flattening
+	b := (1 - f) * a
+	sinAlpha1, cosAlpha1 := math.Sincos(bearing1)
+	tanU1 := (1 - f) * math.Tan(lat1)
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	sigma1 := math.Atan2(tanU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cos2Alpha := 1 - sinAlpha*sinAlpha
+	u2 := cos2Alpha * (a*a - b*b) / (b * b)
+	A, B := vincentyAB(u2)
+
+	sigma := distance / (b * A)
+	var sinSigma, cosSigma, cos2SigmaM float64
+	for i := 0; i < maxIter; i++ {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+		deltaSigma := vincentyDeltaSigma(B, sinSigma, cosSigma, cos2SigmaM)
+		prev := sigma
+		sigma = distance/(b*A) + deltaSigma
+		if math.Abs(sigma-prev) < tol {
+			break
+		}
+	}
+	sinSigma, cosSigma = math.Sincos(sigma)
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+	tmp := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	lat2 = math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-f)*math.Hypot(sinAlpha, tmp))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	C := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+	L := lambda - (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+	long2 = clampLongLat(long1 + L)
+	bearing2 = math.Atan2(sinAlpha, -tmp)
+	return lat2, long2, wrapBearing(bearing2)
+}
+
+// GroundRange returns the distance [m] along the reference sphere between g and target
+// and the initial and final bearings [rad]. See [World.GreatCircleInverse].
+func (g GeocentricCoords) GroundRange(target GeocentricCoords) (distance, bearing1, bearing2 float64) {
+	return g.w.GreatCircleInverse(g.Lat, g.Long, target.Lat, target.Long)
+}
+
+func vincentyAB(u2 float64) (A, B float64) {
+	A = 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+	B = u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+	return A, B
+}
+
+func vincentyDeltaSigma(B, sinSigma, cosSigma, cos2SigmaM float64) float64 {
+	c2 := cos2SigmaM * cos2SigmaM
+	return B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*c2)-B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*c2)))
+}
+
+// wrapBearing limits a bearing to range [0, 2pi).
+func wrapBearing(rad float64) float64 {
+	rad = math.Mod(rad, 2*math.Pi)
+	if rad < 0 {
+		rad += 2 * math.Pi
+	}
+	return rad
+}
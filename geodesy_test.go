@@ -0,0 +1,65 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+)
+
+func TestGeodesicVincenty(t *testing.T) {
+	// Flinders Peak to Buninyong, from Vincenty's original paper (1975).
+	dms := func(deg, min, sec float64) float64 {
+		sign := 1.0
+		if deg < 0 {
+			sign, deg = -1, -deg
+		}
+		return sign * (deg + min/60 + sec/3600) * math.Pi / 180
+	}
+	lat1, long1 := dms(-37, 57, 3.72030), dms(144, 25, 29.52440)
+	lat2, long2 := dms(-37, 39, 10.15610), dms(143, 55, 35.38390)
+	const wantDistance = 54972.271
+	wantBearing1 := dms(306, 52, 5.37)
+	wantBearing2 := dms(127, 10, 25.07) + math.Pi // Paper gives reverse azimuth.
+	const angTol = 0.01 / 3600 * math.Pi / 180    // 0.01 arcsecond.
+
+	earth := NewEarth()
+	distance, bearing1, bearing2, err := earth.GeodesicInverse(lat1, long1, lat2, long2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !md1.EqualWithinAbs(distance, wantDistance, 1e-3) {
+		t.Errorf("distance: want %.4f, got %.4f", wantDistance, distance)
+	}
+	if !md1.EqualWithinAbs(bearing1, wantBearing1, angTol) || !md1.EqualWithinAbs(bearing2, wantBearing2, angTol) {
+		t.Errorf("bearings: want (%g,%g), got (%g,%g)", wantBearing1, wantBearing2, bearing1, bearing2)
+	}
+
+	gotLat2, gotLong2, gotBearing2 := earth.GeodesicDirect(lat1, long1, wantBearing1, wantDistance)
+	if !md1.EqualWithinAbs(gotLat2, lat2, angTol) || !md1.EqualWithinAbs(gotLong2, long2, angTol) {
+		t.Errorf("direct: want (%g,%g), got (%g,%g)", lat2, long2, gotLat2, gotLong2)
+	}
+	if !md1.EqualWithinAbs(gotBearing2, wantBearing2, angTol) {
+		t.Errorf("direct final bearing: want %g, got %g", wantBearing2, gotBearing2)
+	}
+}
+
+func TestGreatCircle(t *testing.T) {
+	earth := NewEarth()
+	quarter := math.Pi / 2 * earth.Radius
+	distance, bearing1, bearing2 := earth.GreatCircleInverse(0, 0, 0, math.Pi/2)
+	if !md1.EqualWithinAbs(distance, quarter, 1e-6) || !md1.EqualWithinAbs(bearing1, math.Pi/2, 1e-12) || !md1.EqualWithinAbs(bearing2, math.Pi/2, 1e-12) {
+		t.Errorf("equator: got distance=%g bearing1=%g bearing2=%g", distance, bearing1, bearing2)
+	}
+	distance, bearing1, _ = earth.GreatCircleInverse(0, 0, math.Pi/2, 0)
+	if !md1.EqualWithinAbs(distance, quarter, 1e-6) || !md1.EqualWithinAbs(bearing1, 0, 1e-12) {
+		t.Errorf("meridian: got distance=%g bearing1=%g", distance, bearing1)
+	}
+
+	const lat1, long1, wantBearing, wantDistance = -0.6, -1.0, 0.7, 1200e3
+	lat2, long2, bearing2 := earth.GreatCircleDirect(lat1, long1, wantBearing, wantDistance)
+	distance, bearing1, gotBearing2 := earth.GreatCircleInverse(lat1, long1, lat2, long2)
+	if !md1.EqualWithinAbs(distance, wantDistance, 1e-6) || !md1.EqualWithinAbs(bearing1, wantBearing, 1e-9) || !md1.EqualWithinAbs(bearing2, gotBearing2, 1e-9) {
+		t.Errorf("round trip: got distance=%g bearing1=%g bearing2=%g/%g", distance, bearing1, bearing2, gotBearing2)
+	}
+}
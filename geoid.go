@@ -0,0 +1,162 @@
+package gnco
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Geoid models the equipotential surface of a world's gravity field that best fits mean sea level.
+type Geoid interface {
+	// Undulation returns the geoid height N above the reference ellipsoid [m]
+	// at a geodetic latitude and longitude [rad].
+	Undulation(geodeticLat, long float64) float64
+}
+
+var _ Geoid = (*GeoidGrid)(nil)
+
+// GeoidGrid is a regular latitude/longitude grid of geoid undulations such as the EGM96 15' grid.
+type GeoidGrid struct {
+	// Interpolation method used by Undulation. Defaults to bilinear.
+	Interpolation GridInterpolation
+	grid          latLongGrid
+}
+
+// NewGeoidGrid returns a geoid grid with undulations [m] stored row-major with nlong values per row.
+// The first node is at lat0Deg, long0Deg and subsequent nodes are spaced by dlatDeg and dlongDeg.
+// dlatDeg may be negative to describe grids stored from North to South.
+func NewGeoidGrid(lat0Deg, long0Deg, dlatDeg, dlongDeg float64, nlat, nlong int, undulations []float64) (*GeoidGrid, error) {
+	grid, err := newLatLongGrid(lat0Deg, long0Deg, dlatDeg, dlongDeg, nlat, nlong, undulations)
+	if err != nil {
+		return nil, err
+	}
+	return &GeoidGrid{grid: grid}, nil
+}
+
+// LoadEGM96Grid reads a geoid grid in the text format NGA distributes the EGM96 15' undulation
+// grid (WW15MGH.GRD). The header holds the South, North, West and East bounds followed by the
+// latitude and longitude spacing in degrees. Data follows row by row from North to South,
+// each row from West to East.
+func LoadEGM96Grid(r io.Reader) (*GeoidGrid, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	var header [6]float64
+	var values []float64
+	for scanner.Scan() {
+		v, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing geoid grid: %w", err)
+		}
+		if nh := len(values); nh < len(header) {
+			header[nh] = v
+		}
+		values = append(values, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	} else if len(values) < len(header) {
+		return nil, errors.New("geoid grid header too short")
+	}
+	south, north, west, east, dlat, dlong := header[0], header[1], header[2], header[3], header[4], header[5]
+	if dlat <= 0 || dlong <= 0 || north <= south || east <= west {
+		return nil, errors.New("bad geoid grid header")
+	}
+	nlat := int(math.Round((north-south)/dlat)) + 1
+	nlong := int(math.Round((east-west)/dlong)) + 1
+	return NewGeoidGrid(north, west, -dlat, dlong, nlat, nlong, values[len(header):])
+}
+
+// Undulation returns the geoid height above the reference ellipsoid [m] at the
+// geodetic latitude and longitude [rad]. Points outside of a regional grid are clamped to its edges.
+func (g *GeoidGrid) Undulation(geodeticLat, long float64) float64 {
+	return g.grid.interpolate(g.Interpolation, geodeticLat, long)
+}
+
+// eccentricitySquared returns the first eccentricity squared of the reference ellipsoid.
+func (w *World) eccentricitySquared() float64 {
+	return w.flattening * (2 - w.flattening)
+}
+
+// EarthFixedFromGeodetic returns the planet-fixed (ECEF) coordinates [m] of a point at a geodetic latitude,
+// longitude [rad] and height above the reference ellipsoid [m].
+func (w *World) EarthFixedFromGeodetic(geodeticLat, long, ellipsoidalHeight float64) (sBIE md3.Vec) {
+	slat, clat := math.Sincos(geodeticLat)
+	slon, clon := math.Sincos(long)
+	e2 := w.eccentricitySquared()
+	N := w.SemiMajorAxis / math.Sqrt(1-e2*slat*slat) // Prime vertical radius of curvature.
+	return md3.Vec{
+		X: (N + ellipsoidalHeight) * clat * clon,
+		Y: (N + ellipsoidalHeight) * clat * slon,
+		Z: (N*(1-e2) + ellipsoidalHeight) * slat,
+	}
+}
+
+// GeodeticFromEarthFixed is the inverse of [World.EarthFixedFromGeodetic].
+func (w *World) GeodeticFromEarthFixed(sBIE md3.Vec) (geodeticLat, long, ellipsoidalHeight float64) {
+	const maxIter = 10
+	e2 := w.eccentricitySquared()
+	a := w.SemiMajorAxis
+	p := math.Hypot(sBIE.X, sBIE.Y)
+	long = math.Atan2(sBIE.Y, sBIE.X)
+	geodeticLat = math.Atan2(sBIE.Z, p*(1-e2))
+	for i := 0; i < maxIter; i++ {
+		slat := math.Sin(geodeticLat)
+		N := a / math.Sqrt(1-e2*slat*slat)
+		prev := geodeticLat
+		geodeticLat = math.Atan2(sBIE.Z+e2*N*slat, p)
+		if math.Abs(geodeticLat-prev) < 1e-14 {
+			break
+		}
+	}
+	slat, clat := math.Sincos(geodeticLat)
+	// Height formulation well behaved at poles and equator.
+	ellipsoidalHeight = p*clat + sBIE.Z*slat - a*math.Sqrt(1-e2*slat*slat)
+	return geodeticLat, long, ellipsoidalHeight
+}
+
+// GeocentricFromGeodetic returns geocentric coordinates of a point at a geodetic latitude,
+// longitude [rad] and height above the reference ellipsoid [m].
+func (w *World) GeocentricFromGeodetic(geodeticLat, long, ellipsoidalHeight float64) GeocentricCoords {
+	return w.GeocentricFromEarthFixedCoords(w.EarthFixedFromGeodetic(geodeticLat, long, ellipsoidalHeight))
+}
+
+// GeocentricFromHASL returns geocentric coordinates of a point at a geodetic latitude,
+// longitude [rad] and orthometric height (height above mean sea level) [m] using w's Geoid.
+func (w *World) GeocentricFromHASL(geodeticLat, long, hasl float64) GeocentricCoords {
+	return w.GeocentricFromGeodetic(geodeticLat, long, w.EllipsoidalHeight(geodeticLat, long, hasl))
+}
+
+// EllipsoidalHeight converts orthometric height (height above mean sea level) to height above
+// the reference ellipsoid [m] at a geodetic latitude and longitude [rad]. If w has no Geoid
+// the mean sea level is taken to be the reference ellipsoid.
+func (w *World) EllipsoidalHeight(geodeticLat, long, hasl float64) float64 {
+	if w.Geoid == nil {
+		return hasl
+	}
+	return hasl + w.Geoid.Undulation(geodeticLat, long)
+}
+
+// OrthometricHeight converts height above the reference ellipsoid to orthometric height
+// (height above mean sea level) [m]. It is the inverse of [World.EllipsoidalHeight].
+func (w *World) OrthometricHeight(geodeticLat, long, ellipsoidalHeight float64) float64 {
+	if w.Geoid == nil {
+		return ellipsoidalHeight
+	}
+	return ellipsoidalHeight - w.Geoid.Undulation(geodeticLat, long)
+}
+
+// Geodetic returns the geodetic latitude, longitude [rad] and height above reference ellipsoid [m] of g.
+func (g GeocentricCoords) Geodetic() (geodeticLat, long, ellipsoidalHeight float64) {
+	return g.w.GeodeticFromEarthFixed(g.EarthFixedCoords())
+}
+
+// HASL returns the orthometric height of g, or height above mean sea level [m], using the world's Geoid.
+func (g GeocentricCoords) HASL() float64 {
+	lat, long, h := g.Geodetic()
+	return g.w.OrthometricHeight(lat, long, h)
+}
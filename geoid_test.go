@@ -0,0 +1,88 @@
+package gnco
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestGeodeticEarthFixed(t *testing.T) {
+	earth := NewEarth()
+	const deg = math.Pi / 180
+	// WGS84 reference values.
+	got := earth.EarthFixedFromGeodetic(45*deg, 0, 0)
+	want := md3.Vec{X: 4517590.878849, Y: 0, Z: 4487348.408866}
+	if !md3.EqualElem(got, want, 1e-5) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	for _, test := range []struct{ lat, long, h float64 }{
+		{lat: 0, long: 0, h: 0},
+		{lat: -34.6 * deg, long: -58.4 * deg, h: 25},
+		{lat: 89.99 * deg, long: 120 * deg, h: 8000},
+		{lat: -60 * deg, long: 179 * deg, h: 400e3},
+	} {
+		lat, long, h := earth.GeodeticFromEarthFixed(earth.EarthFixedFromGeodetic(test.lat, test.long, test.h))
+		if !md1.EqualWithinAbs(lat, test.lat, 1e-12) || !md1.EqualWithinAbs(long, test.long, 1e-12) || !md1.EqualWithinAbs(h, test.h, 1e-6) {
+			t.Errorf("round trip: want (%g,%g,%g), got (%g,%g,%g)", test.lat, test.long, test.h, lat, long, h)
+		}
+		g := earth.GeocentricFromGeodetic(test.lat, test.long, test.h)
+		lat, long, h = g.Geodetic()
+		if !md1.EqualWithinAbs(lat, test.lat, 1e-12) || !md1.EqualWithinAbs(h, test.h, 1e-6) {
+			t.Errorf("geocentric round trip: want (%g,%g,%g), got (%g,%g,%g)", test.lat, test.long, test.h, lat, long, h)
+		}
+	}
+}
+
+func TestGeoidGrid(t *testing.T) {
+	// 3x5 global grid in EGM96 text format at 90 degree spacing.
+	const grd = ` -90.000000 90.000000 0.000000 360.000000 90.000000 90.000000
+ 10 10 10 10 10
+
+ 0 20 40 20 0
+
+ -10 -10 -10 -10 -10
+`
+	geoid, err := LoadEGM96Grid(strings.NewReader(grd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const deg = math.Pi / 180
+	for _, test := range []struct {
+		lat, long, want float64
+	}{
+		{lat: 0, long: 90 * deg, want: 20},
+		{lat: 0, long: 180 * deg, want: 40},
+		{lat: 0, long: -180 * deg, want: 40},
+		{lat: 0, long: -90 * deg, want: 20},
+		{lat: 0, long: 45 * deg, want: 10},
+		{lat: 45 * deg, long: 90 * deg, want: 15},
+		{lat: 90 * deg, long: 33 * deg, want: 10},
+	} {
+		got := geoid.Undulation(test.lat, test.long)
+		if !md1.EqualWithinAbs(got, test.want, 1e-6) {
+			t.Errorf("bilinear (%g,%g): want %g, got %g", test.lat/deg, test.long/deg, test.want, got)
+		}
+	}
+	geoid.Interpolation = InterpBicubic
+	for _, long := range []float64{0, 90, 180, 270} {
+		got := geoid.Undulation(0, long*deg)
+		want := geoid.grid.node(1, int(long/90))
+		if !md1.EqualWithinAbs(got, want, 1e-6) {
+			t.Errorf("bicubic at node %g: want %g, got %g", long, want, got)
+		}
+	}
+
+	earth := NewEarth()
+	earth.Geoid = geoid
+	const lat, long, hasl = 0, 90 * deg, 100
+	g := earth.GeocentricFromHASL(lat, long, hasl)
+	if got := g.HASL(); !md1.EqualWithinAbs(got, hasl, 1e-6) {
+		t.Errorf("HASL: want %g, got %g", float64(hasl), got)
+	}
+	if _, _, h := g.Geodetic(); !md1.EqualWithinAbs(h, hasl+20, 1e-6) {
+		t.Errorf("ellipsoidal height: want %g, got %g", float64(hasl+20), h)
+	}
+}
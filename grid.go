@@ -0,0 +1,106 @@
+package gnco
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// GridInterpolation selects the method used to interpolate between nodes of a
+// regular latitude/longitude grid such as [GeoidGrid] or [TerrainGrid].
+type GridInterpolation uint8
+
+const (
+	// InterpBilinear interpolates linearly between the 4 surrounding grid nodes.
+	InterpBilinear GridInterpolation = iota
+	// InterpBicubic interpolates with a Catmull-Rom spline over the 16 surrounding grid nodes.
+	InterpBicubic
+)
+
+// latLongGrid stores values on a regular latitude/longitude grid.
+type latLongGrid struct {
+	lat0, long0 float64 // Position of first grid node [deg].
+	dlat, dlong float64 // Grid spacing [deg]. dlat is negative for grids stored North to South.
+	nlat, nlong int
+	// period is the amount of unique longitude columns when the grid wraps around
+	// the whole world, or zero if the grid is regional.
+	period int
+	v      []float32 // Row-major values.
+}
+
+func newLatLongGrid(lat0Deg, long0Deg, dlatDeg, dlongDeg float64, nlat, nlong int, values []float64) (latLongGrid, error) {
+	if nlat < 2 || nlong < 2 {
+		return latLongGrid{}, errors.New("grid must have at least 2x2 nodes")
+	} else if len(values) != nlat*nlong {
+		return latLongGrid{}, fmt.Errorf("grid expected %d values, got %d", nlat*nlong, len(values))
+	} else if dlatDeg == 0 || dlongDeg <= 0 {
+		return latLongGrid{}, errors.New("bad grid spacing")
+	}
+	g := latLongGrid{
+		lat0:  lat0Deg,
+		long0: long0Deg,
+		dlat:  dlatDeg,
+		dlong: dlongDeg,
+		nlat:  nlat,
+		nlong: nlong,
+		v:     make([]float32, len(values)),
+	}
+	for i, v := range values {
+		g.v[i] = float32(v)
+	}
+	if period := math.Round(360 / dlongDeg); float64(nlong) >= period {
+		g.period = int(period)
+	}
+	return g, nil
+}
+
+// interpolate returns the grid value at latitude and longitude [rad].
+// Points outside of a regional grid are clamped to its edges.
+func (g *latLongGrid) interpolate(method GridInterpolation, lat, long float64) float64 {
+	y := (lat*180/math.Pi - g.lat0) / g.dlat
+	x := (long*180/math.Pi - g.long0) / g.dlong
+	if g.period > 0 {
+		x = math.Mod(x, float64(g.period))
+		if x < 0 {
+			x += float64(g.period)
+		}
+	} else {
+		x = math.Max(0, math.Min(x, float64(g.nlong-1)))
+	}
+	y = math.Max(0, math.Min(y, float64(g.nlat-1)))
+	i, j := math.Floor(y), math.Floor(x)
+	fy, fx := y-i, x-j
+	ii, jj := int(i), int(j)
+	switch method {
+	case InterpBicubic:
+		var rows [4]float64
+		for k := range rows {
+			rows[k] = catmullRom(g.node(ii+k-1, jj-1), g.node(ii+k-1, jj), g.node(ii+k-1, jj+1), g.node(ii+k-1, jj+2), fx)
+		}
+		return catmullRom(rows[0], rows[1], rows[2], rows[3], fy)
+	default:
+		v0 := g.node(ii, jj) + fx*(g.node(ii, jj+1)-g.node(ii, jj))
+		v1 := g.node(ii+1, jj) + fx*(g.node(ii+1, jj+1)-g.node(ii+1, jj))
+		return v0 + fy*(v1-v0)
+	}
+}
+
+// node returns the value at latitude index i and longitude index j
+// wrapping longitude for global grids and clamping otherwise.
+func (g *latLongGrid) node(i, j int) float64 {
+	i = max(0, min(i, g.nlat-1))
+	if g.period > 0 {
+		j %= g.period
+		if j < 0 {
+			j += g.period
+		}
+	} else {
+		j = max(0, min(j, g.nlong-1))
+	}
+	return float64(g.v[i*g.nlong+j])
+}
+
+// catmullRom interpolates between p1 and p2 at t in [0,1] using neighbouring p0 and p3.
+func catmullRom(p0, p1, p2, p3, t float64) float64 {
+	return 0.5 * (2*p1 + t*(p2-p0+t*(2*p0-5*p1+4*p2-p3+t*(3*(p1-p2)+p3-p0))))
+}
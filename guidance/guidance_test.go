@@ -0,0 +1,155 @@
+package guidance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+const deg = math.Pi / 180
+
+func TestProportionalNavigationIntercept(t *testing.T) {
+	// Engagement in free space. The target weaves with a sinusoidal acceleration.
+	earth := gnco.NewEarth()
+	origin := md3.Vec{X: earth.SemiMajorAxis + 1000e3}
+	ST0, VT := md3.Add(origin, md3.Vec{Y: 10e3, Z: 2e3}), md3.Vec{Z: -250}
+	const weave, weaveFreq = 30., 0.5
+	targetState := func(t float64) (STI, VTI, ATI md3.Vec) {
+		// Acceleration weave*sin(wt) along Y integrated analytically.
+		w := weaveFreq
+		y := weave / w * (t - math.Sin(w*t)/w)
+		STI = md3.Add(ST0, md3.Vec{Y: y, Z: VT.Z * t})
+		VTI = md3.Vec{Y: weave / w * (1 - math.Cos(w*t)), Z: VT.Z}
+		ATI = md3.Vec{Y: weave * math.Sin(w*t)}
+		return STI, VTI, ATI
+	}
+	for _, law := range []PNLaw{TruePN, PurePN, AugmentedPN} {
+		pn := ProportionalNavigation{Law: law, N: 4}
+		coords := earth.GeocentricFromDegrees(0, 0, 0)
+		phys := gnco.NewPhysicsPointIntegrator(&coords, 0, origin, md3.Vec{Y: 900})
+		phys.RemoveForceModel("gravity")
+		phys.SetMethod(gnco.MethodGRKN54)
+		phys.AddForceModel("guidance", gnco.ForceFunc(func(s *gnco.ForceState) md3.Vec {
+			STI, VTI, ATI := targetState(s.T)
+			return pn.AccelInertial(s.SBI, s.VBI, STI, VTI, ATI)
+		}))
+		const dt = 0.01
+		miss := math.Inf(1)
+		for i := 0; i < 5000; i++ {
+			tp, SPI, VPI := phys.State()
+			STI, VTI, _ := targetState(tp)
+			zem, tgo := ZeroEffortMiss(SPI, VPI, STI, VTI)
+			if tgo < dt {
+				miss = zem
+				break
+			}
+			phys.Step(dt, md3.Vec{})
+		}
+		if miss > 2 {
+			t.Errorf("law %d: miss distance %gm", law, miss)
+		}
+	}
+}
+
+func TestZeroEffortMiss(t *testing.T) {
+	miss, tgo := ZeroEffortMiss(md3.Vec{}, md3.Vec{X: 100}, md3.Vec{X: 1000, Y: 50}, md3.Vec{})
+	if !md1.EqualWithinAbs(miss, 50, 1e-9) || !md1.EqualWithinAbs(tgo, 10, 1e-9) {
+		t.Errorf("want miss 50 in 10s, got %g in %gs", miss, tgo)
+	}
+}
+
+func TestAttitude(t *testing.T) {
+	VBG := md3.Vec{X: 100, Y: 100, Z: -50}
+	dirG := gnco.GeographicVectorFromElevationAndBearing(40*deg, 30*deg, 1)
+	TGI := md3.IdentityMat3()
+	orient := Attitude(dirG, VBG, TGI)
+	if got := gnco.FrameVelocity.ToGeographic(orient, md3.Vec{X: 1}); !md3.EqualElem(got, md3.Unit(VBG), 1e-12) {
+		t.Errorf("velocity frame X axis want %v, got %v", md3.Unit(VBG), got)
+	}
+	if got := gnco.FrameBody.ToGeographic(orient, md3.Vec{X: 1}); !md3.EqualElem(got, dirG, 1e-12) {
+		t.Errorf("body frame X axis want %v, got %v", dirG, got)
+	}
+	// Velocity frame Y axis is horizontal.
+	if got := gnco.FrameVelocity.ToGeographic(orient, md3.Vec{Y: 1}); math.Abs(got.Z) > 1e-12 {
+		t.Errorf("velocity frame Y axis not horizontal: %v", got)
+	}
+	// A thrust command along body X axis in velocity frame has the angle of attack to velocity.
+	cmdV := gnco.FrameBody.ToVelocity(orient, md3.Vec{X: 1})
+	if !md1.EqualWithinAbs(md3.Cos(cmdV, md3.Vec{X: 1}), md3.Cos(dirG, VBG), 1e-12) {
+		t.Errorf("angle between thrust and velocity not preserved")
+	}
+}
+
+func TestSteeringPrograms(t *testing.T) {
+	lt := NewLinearTangent(10, 60*deg, 110, 10*deg, 90*deg)
+	if !md1.EqualWithinAbs(lt.Pitch(10), 60*deg, 1e-12) || !md1.EqualWithinAbs(lt.Pitch(110), 10*deg, 1e-12) {
+		t.Errorf("linear tangent endpoints want (60,10)deg, got (%g,%g)", lt.Pitch(10)/deg, lt.Pitch(110)/deg)
+	}
+	if d := lt.DirectionGeographic(10); !md3.EqualElem(d, gnco.GeographicVectorFromElevationAndBearing(60*deg, 90*deg, 1), 1e-12) {
+		t.Errorf("unexpected linear tangent direction %v", d)
+	}
+	pp, err := NewPitchProgram(5, 0, []float64{0, 10, 20}, []float64{90 * deg, 80 * deg, 50 * deg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct{ t, pitch float64 }{
+		{0, 90}, {5, 90}, {10, 85}, {15, 80}, {20, 65}, {100, 50},
+	} {
+		if got := pp.Pitch(test.t); !md1.EqualWithinAbs(got, test.pitch*deg, 1e-12) {
+			t.Errorf("pitch program t=%g: want %g, got %g", test.t, test.pitch, got/deg)
+		}
+	}
+}
+
+func TestGravityTurnLaunch(t *testing.T) {
+	earth := gnco.NewEarth()
+	const azimuth = 90 * deg
+	program := GravityTurn{VerticalRise: 10, PitchoverDuration: 5, PitchoverAngle: 6 * deg, Azimuth: azimuth}
+	stages := []gnco.Stage{{
+		DryMass: 2000, PropellantMass: 18000,
+		Engine: gnco.Engine{Curve: gnco.ConstantThrust{Thrust: 300e3, Duration: 300}, Isp: 290},
+	}}
+	v, err := gnco.NewVehicle(stages, 500, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Direction = program.ThrustDirection
+	launch := earth.GeocentricFromDegrees(-80.6, 28.5, 0)
+	coords := launch
+	SBI0, _ := launch.InertialCoords(0)
+	VBI0 := md3.Cross(md3.Vec{Z: earth.Rotation}, SBI0)
+	phys := gnco.NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	phys.SetMethod(gnco.MethodGRKN54)
+	flight := gnco.NewFlight(v, phys)
+	var tb float64
+	var SBI, VBI md3.Vec
+	for tb < v.Burnout() {
+		tb, SBI, VBI = flight.Step(1)
+		if tb < 9 {
+			// Vertical rise.
+			up := md3.MulMatVecTrans(launch.TGE(), md3.Vec{Z: -1})
+			dSBE := md3.Sub(md3.MulMatVec(earth.TEI(tb), SBI), launch.EarthFixedCoords())
+			if md3.Norm(dSBE) > 1 && md3.Cos(dSBE, up) < 0.9999 {
+				t.Fatalf("vehicle not rising vertically at t=%g", tb)
+			}
+		}
+	}
+	final := earth.GeocentricFromEarthFixedCoords(md3.MulMatVec(earth.TEI(tb), SBI))
+	TGI := final.TGI(tb)
+	state := gnco.ForceState{T: tb, SBI: SBI, VBI: VBI, TGI: TGI, Coords: &final}
+	VBEG := md3.MulMatVec(TGI, gnco.RelativeVelocity(&state))
+	flightPath, heading, speed := gnco.ElevationAndBearingFromGeographicVector(VBEG)
+	if flightPath > 85*deg || flightPath < 0 {
+		t.Errorf("unexpected flight path angle after gravity turn %g", flightPath/deg)
+	}
+	// Coriolis acceleration deflects the trajectory to the right in the northern hemisphere.
+	if math.Abs(heading-azimuth) > 10*deg {
+		t.Errorf("want heading near launch azimuth %g, got %g", azimuth/deg, heading/deg)
+	}
+	if final.Elev < 50e3 || speed < 2000 {
+		t.Errorf("vehicle did not climb: altitude %gm speed %gm/s", final.Elev, speed)
+	}
+}
@@ -0,0 +1,150 @@
+package guidance
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/orbits"
+)
+
+// PEG is the powered explicit guidance closed-loop law for exoatmospheric ascent to orbit insertion.
+// It steers the thrust within the target orbit plane so that the radial component of the thrust
+// direction varies linearly with time and computes the time to go until engine cutoff at which the
+// target radius, radial velocity and tangential velocity are reached simultaneously.
+// See Jaggers - An explicit solution to the exoatmospheric powered flight guidance and trajectory
+// optimization problem for rocket propelled vehicles (1977).
+//
+// PEG is updated once every guidance cycle with [PEG.Update] and the thrust direction between updates is
+// obtained from [PEG.DirectionInertial] or [PEG.ThrustDirection].
+type PEG struct {
+	// GM is the gravitational parameter of the world [m^3.s^-2].
+	GM float64
+	// Target cutoff conditions.
+	Radius             float64 // Distance to world's center [m].
+	RadialVelocity     float64 // [m/s]
+	TangentialVelocity float64 // [m/s]
+	// Normal is the unit normal of the target orbit plane in inertial frame. If zero
+	// it is set to the normal of the vehicle's orbit plane on the first update.
+	Normal md3.Vec
+	// TerminalTime is the time to go [s] below which the steering coefficients are held
+	// constant since the solution becomes ill conditioned near cutoff.
+	TerminalTime float64
+
+	a, b, c  float64 // Steering coefficients.
+	timeToGo float64
+	tUpdate  float64
+	ok       bool
+}
+
+// NewPEG returns powered explicit guidance targeting insertion at the periapsis of target orbiting
+// a world of gravitational parameter gm [m^3.s^-2].
+func NewPEG(target orbits.Elliptical, gm float64) *PEG {
+	_, vt := target.Velocity(gm, 0)
+	return &PEG{
+		GM:                 gm,
+		Radius:             target.Periapsis(),
+		TangentialVelocity: vt,
+		TerminalTime:       2,
+	}
+}
+
+// Update runs a guidance cycle at epoch time t for a vehicle at inertial position SBI with
+// velocity VBI, current thrust acceleration accel [m.s^-2] and engine exhaust velocity ve [m/s],
+// which is the vacuum specific impulse times [gnco.StandardGravity]. It returns the time to go [s]
+// until engine cutoff. An error is returned if the solution did not converge, in which case the
+// previous steering solution is retained.
+func (p *PEG) Update(t float64, SBI, VBI md3.Vec, accel, ve float64) (timeToGo float64, err error) {
+	if !(accel > 0) || !(ve > 0) {
+		return p.TimeToGo(t), errors.New("PEG requires positive thrust acceleration and exhaust velocity")
+	}
+	if p.Normal == (md3.Vec{}) {
+		p.Normal = md3.Unit(md3.Cross(SBI, VBI))
+	}
+	if p.ok && p.TimeToGo(t) < p.TerminalTime {
+		return p.TimeToGo(t), nil
+	}
+	r := md3.Norm(SBI)
+	rhat := md3.Scale(1/r, SBI)
+	vr := md3.Dot(VBI, rhat)
+	vt := md3.Norm(md3.Sub(VBI, md3.Scale(vr, rhat)))
+	tau := ve / accel
+	T := p.TimeToGo(t)
+	if !p.ok {
+		// Initial estimate from velocity to be gained.
+		dv := math.Abs(p.TangentialVelocity-vt) + math.Abs(p.RadialVelocity-vr)
+		T = tau * (1 - math.Exp(-dv/ve))
+	}
+	const maxIter, tol = 50, 1e-3
+	var A, B, C float64
+	converged := false
+	for i := 0; i < maxIter && !converged; i++ {
+		T = math.Min(T, 0.999*tau) // Burn can't outlast vehicle mass.
+		b0 := -ve * math.Log(1-T/tau)
+		b1 := b0*tau - ve*T
+		c0 := b0*T - b1
+		c1 := c0*tau - ve*T*T/2
+		det := b0*c1 - b1*c0
+		if det == 0 || math.IsNaN(det) {
+			break
+		}
+		dvr := p.RadialVelocity - vr
+		dr := p.Radius - r - vr*T
+		A = (c1*dvr - b1*dr) / det
+		B = (b0*dr - c0*dvr) / det
+
+		// Time to go from angular momentum to be gained.
+		omega := vt / r
+		C = (p.GM/(r*r) - omega*omega*r) / accel
+		aT := accel / (1 - T/tau)
+		omegaT := p.TangentialVelocity / p.Radius
+		CT := (p.GM/(p.Radius*p.Radius) - omegaT*omegaT*p.Radius) / aT
+		fr := A + C
+		frT := A + B*T + CT
+		frdot := (frT - fr) / T
+		ft := 1 - fr*fr/2
+		ftdot := -fr * frdot
+		ftdd := -frdot * frdot / 2
+		dh := p.Radius*p.TangentialVelocity - r*vt
+		rbar := (r + p.Radius) / 2
+		dv := dh/rbar + ve*T*(ftdot+ftdd*tau) + ftdd*ve*T*T/2
+		dv /= ft + ftdot*tau + ftdd*tau*tau
+		Tnew := tau * (1 - math.Exp(-dv/ve))
+		converged = math.Abs(Tnew-T) < tol
+		T = Tnew
+	}
+	if !converged || !(T > 0) {
+		return p.TimeToGo(t), errors.New("PEG did not converge")
+	}
+	p.a, p.b, p.c = A, B, C
+	p.timeToGo, p.tUpdate, p.ok = T, t, true
+	return T, nil
+}
+
+// Converged returns true if a steering solution is available.
+func (p *PEG) Converged() bool { return p.ok }
+
+// TimeToGo returns the time to go until engine cutoff [s] at epoch time t from the last update.
+func (p *PEG) TimeToGo(t float64) float64 {
+	return p.timeToGo - (t - p.tUpdate)
+}
+
+// DirectionInertial returns the unit thrust direction in inertial frame at epoch time t for
+// a vehicle at inertial position SBI. It returns the zero vector before the first successful update.
+func (p *PEG) DirectionInertial(t float64, SBI md3.Vec) md3.Vec {
+	if !p.ok {
+		return md3.Vec{}
+	}
+	rhat := md3.Unit(SBI)
+	downrange := md3.Unit(md3.Cross(p.Normal, rhat))
+	fr := p.a + p.b*(t-p.tUpdate) + p.c
+	fr = math.Max(-1, math.Min(fr, 1))
+	return md3.Add(md3.Scale(fr, rhat), md3.Scale(math.Sqrt(1-fr*fr), downrange))
+}
+
+// ThrustDirection returns the unit thrust direction in inertial frame. It may be used
+// as the Direction of [gnco.Propulsion] or [gnco.Vehicle].
+func (p *PEG) ThrustDirection(s *gnco.ForceState) md3.Vec {
+	return p.DirectionInertial(s.T, s.SBI)
+}
@@ -0,0 +1,101 @@
+package guidance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/orbits"
+)
+
+func TestPEGOrbitInsertion(t *testing.T) {
+	// Two stage launch from Cape Canaveral to a 200km circular orbit. The first stage flies
+	// an open-loop gravity turn and the second stage is guided by PEG to engine cutoff.
+	earth := gnco.NewEarth()
+	const (
+		targetAltitude = 200e3
+		isp1, isp2     = 290., 340.
+		thrust2        = 400e3
+		guidanceCycle  = 1.
+	)
+	target, err := orbits.NewCircular(earth.SemiMajorAxis + targetAltitude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stages := []gnco.Stage{
+		{
+			DryMass: 20e3, PropellantMass: 200e3,
+			Engine:     gnco.Engine{Curve: gnco.ConstantThrust{Thrust: 3.8e6, Duration: 1000}, Isp: isp1},
+			Separation: gnco.StagingEvent{Trigger: gnco.StageOnBurnout},
+		},
+		{
+			DryMass: 3e3, PropellantMass: 27e3, IgnitionDelay: 2,
+			Engine:     gnco.Engine{Curve: gnco.ConstantThrust{Thrust: thrust2, Duration: 1000}, Isp: isp2},
+			Separation: gnco.StagingEvent{Trigger: gnco.StageOnBurnout},
+		},
+	}
+	v, err := gnco.NewVehicle(stages, 2e3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ascent := GravityTurn{VerticalRise: 10, PitchoverDuration: 10, PitchoverAngle: 12 * deg, Azimuth: 90 * deg}
+	peg := NewPEG(target, earth.G())
+	v.Direction = func(s *gnco.ForceState) md3.Vec {
+		if v.ActiveStage() == 0 || !peg.Converged() {
+			return ascent.ThrustDirection(s)
+		}
+		return peg.ThrustDirection(s)
+	}
+	launch := earth.GeocentricFromDegrees(-80.6, 28.5, 0)
+	coords := launch
+	SBI0, _ := launch.InertialCoords(0)
+	VBI0 := md3.Cross(md3.Vec{Z: earth.Rotation}, SBI0)
+	phys := gnco.NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	phys.SetMethod(gnco.MethodGRKN54)
+	flight := gnco.NewFlight(v, phys)
+
+	tt, SBI, VBI := phys.State()
+	cutoff := false
+	for len(flight.Separations) < 2 && tt < 1000 {
+		if v.ActiveStage() == 1 && tt >= v.IgnitionTime() && !cutoff {
+			accel := thrust2 / v.Mass(tt)
+			tgo, err := peg.Update(tt, SBI, VBI, accel, isp2*gnco.StandardGravity)
+			if err != nil {
+				t.Fatalf("t=%g: %v", tt, err)
+			}
+			if tgo < guidanceCycle {
+				v.Cutoff(tt + tgo)
+				cutoff = true
+			}
+		}
+		tt, SBI, VBI = flight.Step(guidanceCycle)
+	}
+	if !cutoff {
+		t.Fatalf("PEG did not command cutoff before second stage burnout. Time to go %g", peg.TimeToGo(tt))
+	}
+	sep := flight.Separations[1]
+	if sep.Mass <= stages[1].DryMass {
+		t.Errorf("second stage exhausted its propellant")
+	}
+	r := md3.Norm(SBI)
+	vr := md3.Dot(VBI, md3.Unit(SBI))
+	vt := md3.Norm(md3.Cross(md3.Unit(SBI), VBI))
+	_, wantVt := target.Velocity(earth.G(), 0)
+	t.Logf("cutoff at t=%.1fs: altitude error %.1fm, radial velocity %.3fm/s, tangential velocity error %.3fm/s, propellant left %.0fkg",
+		sep.T, r-target.Periapsis(), vr, vt-wantVt, sep.Mass-stages[1].DryMass)
+	if math.Abs(r-target.Periapsis()) > 100 {
+		t.Errorf("insertion radius error %gm", r-target.Periapsis())
+	}
+	if math.Abs(vr) > 1 || math.Abs(vt-wantVt) > 1 {
+		t.Errorf("insertion velocity error radial=%g tangential=%g", vr, vt-wantVt)
+	}
+	// The resulting orbit is circular.
+	energy := md3.Norm2(VBI)/2 - earth.G()/r
+	a := -earth.G() / (2 * energy)
+	h := md3.Norm(md3.Cross(SBI, VBI))
+	e := math.Sqrt(math.Max(0, 1-h*h/(earth.G()*a)))
+	if e > 1e-3 {
+		t.Errorf("orbit eccentricity %g", e)
+	}
+}
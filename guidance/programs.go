@@ -0,0 +1,162 @@
+package guidance
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+// GravityTurn is an open-loop launch program. The vehicle rises vertically, pitches over
+// towards the launch azimuth and then follows a gravity turn with thrust along its velocity
+// relative to the world. Pitch angles are measured from the local horizon.
+type GravityTurn struct {
+	LiftoffTime       float64 // Epoch time of liftoff [s].
+	VerticalRise      float64 // Duration of vertical ascent after liftoff [s].
+	PitchoverDuration float64 // Duration of the pitch-over manoeuvre [s].
+	PitchoverAngle    float64 // Angle from vertical the vehicle is tilted by during pitch-over [rad].
+	Azimuth           float64 // Launch azimuth measured clockwise from North [rad].
+}
+
+// DirectionGeographic returns the unit thrust direction in geographic frame at epoch time t
+// for a vehicle with velocity relative to the world VBEG in geographic frame.
+func (g GravityTurn) DirectionGeographic(t float64, VBEG md3.Vec) md3.Vec {
+	tf := t - g.LiftoffTime
+	switch {
+	case tf < g.VerticalRise:
+		return md3.Vec{Z: -1}
+	case tf < g.VerticalRise+g.PitchoverDuration:
+		tilt := g.PitchoverAngle * (tf - g.VerticalRise) / g.PitchoverDuration
+		return gnco.GeographicVectorFromElevationAndBearing(math.Pi/2-tilt, g.Azimuth, 1)
+	}
+	// Hold pitch-over attitude until velocity has tilted beyond it.
+	pitch, bearing, norm := gnco.ElevationAndBearingFromGeographicVector(VBEG)
+	if norm == 0 || math.Hypot(VBEG.X, VBEG.Y) == 0 {
+		bearing = g.Azimuth
+	}
+	pitch = math.Min(pitch, math.Pi/2-g.PitchoverAngle)
+	return gnco.GeographicVectorFromElevationAndBearing(pitch, bearing, 1)
+}
+
+// ThrustDirection returns the unit thrust direction in inertial frame. It may be used
+// as the Direction of [gnco.Propulsion] or [gnco.Vehicle].
+func (g GravityTurn) ThrustDirection(s *gnco.ForceState) md3.Vec {
+	return geographicToInertial(s, g.DirectionGeographic(s.T, relativeVelocityGeographic(s)))
+}
+
+// PitchProgram is an open-loop launch program where the pitch angle above the local horizon
+// is linearly interpolated from a table of times since liftoff. The pitch is held at the
+// first and last table values outside the table's time range.
+type PitchProgram struct {
+	LiftoffTime float64 // Epoch time of liftoff [s].
+	Azimuth     float64 // Launch azimuth measured clockwise from North [rad].
+	time, pitch []float64
+}
+
+// NewPitchProgram returns a pitch program interpolating pitch angles [rad] at strictly increasing times since liftoff [s].
+func NewPitchProgram(liftoffTime, azimuth float64, time, pitch []float64) (*PitchProgram, error) {
+	if len(time) != len(pitch) || len(time) == 0 {
+		return nil, errors.New("pitch program requires equal length non-empty time and pitch tables")
+	}
+	for i := 1; i < len(time); i++ {
+		if !(time[i] > time[i-1]) {
+			return nil, errors.New("pitch program times not strictly increasing")
+		}
+	}
+	return &PitchProgram{
+		LiftoffTime: liftoffTime,
+		Azimuth:     azimuth,
+		time:        slices.Clone(time),
+		pitch:       slices.Clone(pitch),
+	}, nil
+}
+
+// Pitch returns the commanded pitch angle above the local horizon [rad] at epoch time t.
+func (p *PitchProgram) Pitch(t float64) float64 {
+	tf := t - p.LiftoffTime
+	n := len(p.time)
+	if tf <= p.time[0] {
+		return p.pitch[0]
+	} else if tf >= p.time[n-1] {
+		return p.pitch[n-1]
+	}
+	i, found := slices.BinarySearch(p.time, tf)
+	if found {
+		return p.pitch[i]
+	}
+	frac := (tf - p.time[i-1]) / (p.time[i] - p.time[i-1])
+	return p.pitch[i-1] + frac*(p.pitch[i]-p.pitch[i-1])
+}
+
+// DirectionGeographic returns the unit thrust direction in geographic frame at epoch time t.
+func (p *PitchProgram) DirectionGeographic(t float64) md3.Vec {
+	return gnco.GeographicVectorFromElevationAndBearing(p.Pitch(t), p.Azimuth, 1)
+}
+
+// ThrustDirection returns the unit thrust direction in inertial frame. It may be used
+// as the Direction of [gnco.Propulsion] or [gnco.Vehicle].
+func (p *PitchProgram) ThrustDirection(s *gnco.ForceState) md3.Vec {
+	return geographicToInertial(s, p.DirectionGeographic(s.T))
+}
+
+// LinearTangent is the linear tangent steering law where the tangent of the pitch angle above the
+// local horizon varies linearly with time:
+//
+//	tan(pitch) = A + B*(t-T0)
+//
+// It is the optimal steering law for maximum velocity gain in a flat world with uniform gravity.
+type LinearTangent struct {
+	T0      float64 // Reference epoch time [s].
+	A, B    float64 // Linear tangent coefficients [Adim] and [s^-1].
+	Azimuth float64 // Steering azimuth measured clockwise from North [rad].
+}
+
+// NewLinearTangent returns the linear tangent law that steers from pitch0 at epoch time t0 to pitch1 at epoch time t1 [rad].
+// Pitch angles must be within (-pi/2, pi/2).
+func NewLinearTangent(t0, pitch0, t1, pitch1, azimuth float64) LinearTangent {
+	if t1 <= t0 {
+		panic("linear tangent requires t1 > t0")
+	}
+	tan0, tan1 := math.Tan(pitch0), math.Tan(pitch1)
+	return LinearTangent{T0: t0, A: tan0, B: (tan1 - tan0) / (t1 - t0), Azimuth: azimuth}
+}
+
+// Pitch returns the commanded pitch angle above the local horizon [rad] at epoch time t.
+func (lt LinearTangent) Pitch(t float64) float64 {
+	return math.Atan(lt.A + lt.B*(t-lt.T0))
+}
+
+// DirectionGeographic returns the unit thrust direction in geographic frame at epoch time t.
+func (lt LinearTangent) DirectionGeographic(t float64) md3.Vec {
+	return gnco.GeographicVectorFromElevationAndBearing(lt.Pitch(t), lt.Azimuth, 1)
+}
+
+// ThrustDirection returns the unit thrust direction in inertial frame. It may be used
+// as the Direction of [gnco.Propulsion] or [gnco.Vehicle].
+func (lt LinearTangent) ThrustDirection(s *gnco.ForceState) md3.Vec {
+	return geographicToInertial(s, lt.DirectionGeographic(s.T))
+}
+
+// Attitude returns the orientation of a body whose X axis points along the commanded direction dirG
+// in geographic frame with no roll, given the body's velocity VBG in geographic frame. The returned
+// orientation may be used to convert commands to [gnco.FrameVelocity] and [gnco.FrameBody].
+func Attitude(dirG, VBG md3.Vec, TGI md3.Mat3) gnco.Orientation {
+	TVG := gnco.TVGFromVelocity(VBG)
+	// The body frame is the velocity frame of a body moving along dirG.
+	TBG := gnco.TVGFromVelocity(dirG)
+	return gnco.Orientation{
+		TBV: md3.MulMat3(TBG, TVG.Transpose()),
+		TVG: TVG,
+		TGI: TGI,
+	}
+}
+
+func relativeVelocityGeographic(s *gnco.ForceState) md3.Vec {
+	return md3.MulMatVec(s.TGI, gnco.RelativeVelocity(s))
+}
+
+func geographicToInertial(s *gnco.ForceState, dirG md3.Vec) md3.Vec {
+	return md3.MulMatVecTrans(s.TGI, dirG)
+}
@@ -0,0 +1,81 @@
+// Package guidance implements guidance laws that command accelerations or thrust directions for
+// bodies integrated with [gnco.PhysicsPointIntegrator]. Commands are computed in inertial frame
+// and may be converted to the velocity or body frames with [gnco.Frame] methods.
+package guidance
+
+import (
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+// PNLaw selects the proportional navigation variant.
+type PNLaw uint8
+
+const (
+	// TruePN commands acceleration perpendicular to the line of sight proportional to the
+	// closing velocity and line of sight rate.
+	TruePN PNLaw = iota
+	// PurePN commands acceleration perpendicular to the pursuer's velocity proportional
+	// to the pursuer's speed and line of sight rate.
+	PurePN
+	// AugmentedPN is TruePN with an additional term compensating the target's acceleration
+	// perpendicular to the line of sight.
+	AugmentedPN
+)
+
+// ProportionalNavigation is a homing guidance law that commands accelerations proportional
+// to the rotation rate of the line of sight from pursuer to target.
+type ProportionalNavigation struct {
+	Law PNLaw
+	// N is the effective navigation ratio [Adim], typically between 3 and 5.
+	N float64
+}
+
+// AccelInertial returns the commanded acceleration in inertial frame [m.s^-2] for a pursuer at inertial
+// position SPI with velocity VPI against a target at STI with velocity VTI and acceleration ATI.
+// The target acceleration is only used by [AugmentedPN].
+func (pn ProportionalNavigation) AccelInertial(SPI, VPI, STI, VTI, ATI md3.Vec) md3.Vec {
+	r := md3.Sub(STI, SPI)
+	vr := md3.Sub(VTI, VPI)
+	r2 := md3.Norm2(r)
+	if r2 == 0 {
+		return md3.Vec{}
+	}
+	omega := md3.Scale(1/r2, md3.Cross(r, vr)) // Line of sight rate [rad/s].
+	switch pn.Law {
+	case PurePN:
+		return md3.Scale(pn.N, md3.Cross(omega, VPI))
+	case TruePN, AugmentedPN:
+		// Commanded acceleration N*Vc*(omega x r/|r|) with closing velocity Vc=-r.vr/|r|.
+		a := md3.Scale(-pn.N*md3.Dot(r, vr)/r2, md3.Cross(omega, r))
+		if pn.Law == AugmentedPN {
+			// Target acceleration perpendicular to line of sight.
+			aperp := md3.Sub(ATI, md3.Scale(md3.Dot(ATI, r)/r2, r))
+			a = md3.Add(a, md3.Scale(pn.N/2, aperp))
+		}
+		return a
+	default:
+		panic("unknown proportional navigation law")
+	}
+}
+
+// AccelVelocity returns the commanded acceleration of [ProportionalNavigation.AccelInertial] in the
+// pursuer's velocity frame given by orient.
+func (pn ProportionalNavigation) AccelVelocity(orient gnco.Orientation, SPI, VPI, STI, VTI, ATI md3.Vec) md3.Vec {
+	return gnco.FrameInertial.ToVelocity(orient, pn.AccelInertial(SPI, VPI, STI, VTI, ATI))
+}
+
+// ZeroEffortMiss returns the predicted closest approach distance [m] between pursuer and target
+// if neither accelerates from now on, and the time to go until closest approach [s].
+func ZeroEffortMiss(SPI, VPI, STI, VTI md3.Vec) (miss, timeToGo float64) {
+	r := md3.Sub(STI, SPI)
+	vr := md3.Sub(VTI, VPI)
+	v2 := md3.Norm2(vr)
+	if v2 > 0 {
+		timeToGo = -md3.Dot(r, vr) / v2
+	}
+	if timeToGo < 0 {
+		timeToGo = 0
+	}
+	return md3.Norm(md3.Add(r, md3.Scale(timeToGo, vr))), timeToGo
+}
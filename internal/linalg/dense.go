@@ -0,0 +1,218 @@
+// Package linalg implements the small dense matrix operations needed for
+// state estimation and orbit determination.
+package linalg
+
+import (
+	"errors"
+	"math"
+)
+
+// Dense is a row-major dense matrix.
+type Dense struct {
+	r, c int
+	data []float64
+}
+
+// New returns a zeroed r by c matrix.
+func New(r, c int) *Dense {
+	if r <= 0 || c <= 0 {
+		panic("bad matrix dimensions")
+	}
+	return &Dense{r: r, c: c, data: make([]float64, r*c)}
+}
+
+// NewFromRows returns a matrix whose rows are copies of rows.
+func NewFromRows(rows ...[]float64) *Dense {
+	m := New(len(rows), len(rows[0]))
+	for i, row := range rows {
+		if len(row) != m.c {
+			panic("ragged rows")
+		}
+		copy(m.data[i*m.c:], row)
+	}
+	return m
+}
+
+// Identity returns the n by n identity matrix.
+func Identity(n int) *Dense {
+	m := New(n, n)
+	for i := 0; i < n; i++ {
+		m.data[i*n+i] = 1
+	}
+	return m
+}
+
+// Diag returns a square matrix with d on its diagonal.
+func Diag(d ...float64) *Dense {
+	m := New(len(d), len(d))
+	for i, v := range d {
+		m.Set(i, i, v)
+	}
+	return m
+}
+
+// Dims returns the number of rows and columns of m.
+func (m *Dense) Dims() (r, c int) { return m.r, m.c }
+
+// At returns the element at row i and column j.
+func (m *Dense) At(i, j int) float64 { return m.data[i*m.c+j] }
+
+// Set sets the element at row i and column j.
+func (m *Dense) Set(i, j int, v float64) { m.data[i*m.c+j] = v }
+
+// Row returns a view of row i.
+func (m *Dense) Row(i int) []float64 { return m.data[i*m.c : (i+1)*m.c] }
+
+// Clone returns a copy of m.
+func (m *Dense) Clone() *Dense {
+	c := *m
+	c.data = append([]float64(nil), m.data...)
+	return &c
+}
+
+// T returns the transpose of m.
+func (m *Dense) T() *Dense {
+	t := New(m.c, m.r)
+	for i := 0; i < m.r; i++ {
+		for j := 0; j < m.c; j++ {
+			t.data[j*m.r+i] = m.data[i*m.c+j]
+		}
+	}
+	return t
+}
+
+// Mul returns the matrix product a*b.
+func Mul(a, b *Dense) *Dense {
+	if a.c != b.r {
+		panic("matrix dimension mismatch in Mul")
+	}
+	m := New(a.r, b.c)
+	for i := 0; i < a.r; i++ {
+		for k := 0; k < a.c; k++ {
+			aik := a.data[i*a.c+k]
+			if aik == 0 {
+				continue
+			}
+			for j := 0; j < b.c; j++ {
+				m.data[i*m.c+j] += aik * b.data[k*b.c+j]
+			}
+		}
+	}
+	return m
+}
+
+// MulVec returns the product a*v.
+func MulVec(a *Dense, v []float64) []float64 {
+	if a.c != len(v) {
+		panic("matrix dimension mismatch in MulVec")
+	}
+	out := make([]float64, a.r)
+	for i := range out {
+		for j, vj := range v {
+			out[i] += a.data[i*a.c+j] * vj
+		}
+	}
+	return out
+}
+
+// Add returns a + s*b.
+func Add(a *Dense, s float64, b *Dense) *Dense {
+	if a.r != b.r || a.c != b.c {
+		panic("matrix dimension mismatch in Add")
+	}
+	m := a.Clone()
+	for i := range m.data {
+		m.data[i] += s * b.data[i]
+	}
+	return m
+}
+
+// Symmetrize sets m to (m + mᵀ)/2 to remove round-off asymmetry. m must be square.
+func (m *Dense) Symmetrize() {
+	for i := 0; i < m.r; i++ {
+		for j := i + 1; j < m.c; j++ {
+			v := (m.At(i, j) + m.At(j, i)) / 2
+			m.Set(i, j, v)
+			m.Set(j, i, v)
+		}
+	}
+}
+
+var errNotPositiveDefinite = errors.New("matrix not positive definite")
+
+// Cholesky returns the lower triangular matrix L such that m = L*Lᵀ.
+func Cholesky(m *Dense) (*Dense, error) {
+	n := m.r
+	if m.c != n {
+		panic("Cholesky of non square matrix")
+	}
+	L := New(n, n)
+	for j := 0; j < n; j++ {
+		d := m.At(j, j)
+		for k := 0; k < j; k++ {
+			d -= L.At(j, k) * L.At(j, k)
+		}
+		if !(d > 0) {
+			return nil, errNotPositiveDefinite
+		}
+		d = math.Sqrt(d)
+		L.Set(j, j, d)
+		for i := j + 1; i < n; i++ {
+			s := m.At(i, j)
+			for k := 0; k < j; k++ {
+				s -= L.At(i, k) * L.At(j, k)
+			}
+			L.Set(i, j, s/d)
+		}
+	}
+	return L, nil
+}
+
+// Inverse returns the inverse of square matrix m using Gauss-Jordan elimination with partial pivoting.
+func Inverse(m *Dense) (*Dense, error) {
+	n := m.r
+	if m.c != n {
+		panic("Inverse of non square matrix")
+	}
+	a := m.Clone()
+	inv := Identity(n)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for i := col + 1; i < n; i++ {
+			if math.Abs(a.At(i, col)) > math.Abs(a.At(pivot, col)) {
+				pivot = i
+			}
+		}
+		p := a.At(pivot, col)
+		if p == 0 || math.IsNaN(p) {
+			return nil, errors.New("singular matrix")
+		}
+		a.swapRows(col, pivot)
+		inv.swapRows(col, pivot)
+		for j := 0; j < n; j++ {
+			a.Set(col, j, a.At(col, j)/p)
+			inv.Set(col, j, inv.At(col, j)/p)
+		}
+		for i := 0; i < n; i++ {
+			f := a.At(i, col)
+			if i == col || f == 0 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				a.Set(i, j, a.At(i, j)-f*a.At(col, j))
+				inv.Set(i, j, inv.At(i, j)-f*inv.At(col, j))
+			}
+		}
+	}
+	return inv, nil
+}
+
+func (m *Dense) swapRows(i, j int) {
+	if i == j {
+		return
+	}
+	ri, rj := m.Row(i), m.Row(j)
+	for k := range ri {
+		ri[k], rj[k] = rj[k], ri[k]
+	}
+}
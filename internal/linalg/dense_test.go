@@ -0,0 +1,44 @@
+package linalg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInverseCholesky(t *testing.T) {
+	a := NewFromRows(
+		[]float64{4, 12, -16},
+		[]float64{12, 37, -43},
+		[]float64{-16, -43, 98},
+	)
+	L, err := Cholesky(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantL := NewFromRows([]float64{2, 0, 0}, []float64{6, 1, 0}, []float64{-8, 5, 3})
+	assertEqual(t, L, wantL, 1e-12)
+	assertEqual(t, Mul(L, L.T()), a, 1e-12)
+	inv, err := Inverse(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, Mul(a, inv), Identity(3), 1e-9)
+	if _, err := Cholesky(Diag(1, -1)); err == nil {
+		t.Error("expected error for indefinite matrix")
+	}
+	if _, err := Inverse(NewFromRows([]float64{1, 2}, []float64{2, 4})); err == nil {
+		t.Error("expected error for singular matrix")
+	}
+}
+
+func assertEqual(t *testing.T, got, want *Dense, tol float64) {
+	t.Helper()
+	r, c := want.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if math.Abs(got.At(i, j)-want.At(i, j)) > tol {
+				t.Fatalf("element (%d,%d) want %g, got %g", i, j, want.At(i, j), got.At(i, j))
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package ode
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+const grkn54Len = 7
+
+// IVP2General is a second order initial value problem whose second derivative may depend on the first derivative.
+type IVP2General struct {
+	Y0  md3.Vec
+	DY0 md3.Vec
+	T0  float64
+	// Func are the second derivatives of the solution such that
+	//  dst = y''(t) = Func(t, y(t), y'(t))
+	// The function call is vectorised such that len(yppDst)==len(t)==len(y)==len(dy)
+	// and after Func call ends yppDst must have evaluation of second order derivative of solution.
+	Func func(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec)
+	// Jac is optional and returns the partial derivatives of Func with respect to y and dy at (t, y, dy).
+	// If set the variational equations are integrated alongside the solution, see [GRKN54.Variational].
+	Jac func(t float64, y, dy md3.Vec) (jy, jdy md3.Mat3)
+}
+
+// GRKN54 is a general Runge-Kutta-Nyström 5(4) integration scheme for second-order differential
+// equation systems whose second derivative depends on the solution's first derivative:
+//
+//	y''(t) = f(t, y(t), y'(t))
+//
+// Its coefficients are derived from the Dormand-Prince 5(4) tableau so that stage first derivatives
+// are available to the second derivative function. It is of lower order than [RKN1210] and thus
+// requires smaller steps for the same accuracy.
+type GRKN54 struct {
+	dom   float64
+	relax float64
+	y, dy md3.Vec
+	f     [grkn54Len]md3.Vec
+	auxv  [grkn54Len]md3.Vec
+	auxdv [grkn54Len]md3.Vec
+	auxt  [grkn54Len]float64
+	// Step control.
+	atol, minStep, maxStep float64
+	fx                     func(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec)
+	// Variational equations: partial derivatives of y and dy with respect to y0 and dy0.
+	jac     func(t float64, y, dy md3.Vec) (jy, jdy md3.Mat3)
+	vy, vdy [2]md3.Mat3
+	vf      [grkn54Len][2]md3.Mat3
+}
+
+func NewGRKN54(relax float64, cfg Parameters) *GRKN54 {
+	if (cfg.AbsTolerance != 0 && cfg.MaxStep <= 0) || cfg.MaxStep < cfg.MinStep ||
+		cfg.MinStep < 0 {
+		panic("invalid parameters supplied")
+	} else if relax <= 0 || relax >= 1 {
+		panic("bad relax factor")
+	}
+	return &GRKN54{
+		atol:    cfg.AbsTolerance,
+		minStep: cfg.MinStep,
+		maxStep: cfg.MaxStep,
+		relax:   relax,
+	}
+}
+
+func (rk *GRKN54) Init(ivp IVP2General) {
+	*rk = GRKN54{
+		relax:   rk.relax,
+		atol:    rk.atol,
+		minStep: rk.minStep,
+		maxStep: rk.maxStep,
+	}
+	rk.fx = ivp.Func
+	rk.jac = ivp.Jac
+	rk.SetVariational(IdentityVariational())
+	rk.dom, rk.y, rk.dy = ivp.T0, ivp.Y0, ivp.DY0
+}
+
+func (rk *GRKN54) State() (t float64, y, dy md3.Vec) {
+	return rk.dom, rk.y, rk.dy
+}
+
+func (rk *GRKN54) SetState(t float64, y, dy md3.Vec) {
+	rk.dom, rk.y, rk.dy = t, y, dy
+}
+
+// Variational returns the partial derivatives of the solution with respect to the initial conditions:
+//
+//	Y = [∂y/∂y0, ∂y/∂dy0]
+//	DY = [∂dy/∂y0, ∂dy/∂dy0]
+//
+// They are only integrated if the initial value problem's Jac is set.
+func (rk *GRKN54) Variational() (Y, DY [2]md3.Mat3) {
+	return rk.vy, rk.vdy
+}
+
+// SetVariational sets the partial derivatives of the solution with respect to the initial conditions.
+func (rk *GRKN54) SetVariational(Y, DY [2]md3.Mat3) {
+	rk.vy, rk.vdy = Y, DY
+}
+
+// Step advances the solution by h and returns the step to use next, which differs from h
+// only when adaptive stepping is enabled.
+func (rk *GRKN54) Step(h float64) (float64, error) {
+	adaptive := rk.atol > 0
+	hnext := h
+	y, dy, t := rk.y, rk.dy, rk.dom
+	F := &rk.f
+	var hFbp, hFb, errY, errDY md3.Vec
+SOLVE:
+	h2 := h * h
+	for j := range F {
+		// y[j] = y + h*c[j]*dy + h*h*sum(abar[j][k]*F[k])
+		// dy[j] = dy + h*sum(a[j][k]*F[k])
+		hc := h * dp54c[j]
+		yj := md3.Add(y, md3.Scale(hc, dy))
+		dyj := dy
+		for k := 0; k < j; k++ {
+			yj = md3.Add(yj, md3.Scale(h2*grkn54A[j][k], F[k]))
+			dyj = md3.Add(dyj, md3.Scale(h*dp54A[j][k], F[k]))
+		}
+		rk.auxv[j], rk.auxdv[j], rk.auxt[j] = yj, dyj, t+hc
+		// Stages depend on the evaluation of all previous stages so they are evaluated in sequence.
+		rk.fx(F[j:j+1], rk.auxt[j:j+1], rk.auxv[j:j+1], rk.auxdv[j:j+1])
+		if rk.jac != nil {
+			// Stage derivatives of the variational equations Y'' = Jy*Y + Jdy*Y'.
+			Jy, Jdy := rk.jac(rk.auxt[j], yj, dyj)
+			for k := range rk.vy {
+				Y := md3.AddMat3(rk.vy[k], md3.ScaleMat3(rk.vdy[k], hc))
+				DY := rk.vdy[k]
+				for i := 0; i < j; i++ {
+					Y = md3.AddMat3(Y, md3.ScaleMat3(rk.vf[i][k], h2*grkn54A[j][i]))
+					DY = md3.AddMat3(DY, md3.ScaleMat3(rk.vf[i][k], h*dp54A[j][i]))
+				}
+				rk.vf[j][k] = md3.AddMat3(md3.MulMat3(Jy, Y), md3.MulMat3(Jdy, DY))
+			}
+		}
+	}
+	hFbp, hFb, errY, errDY = md3.Vec{}, md3.Vec{}, md3.Vec{}, md3.Vec{}
+	for j, fj := range F {
+		hFbp = md3.Add(hFbp, md3.Scale(h*dp54b[j], fj))
+		hFb = md3.Add(hFb, md3.Scale(h*grkn54b[j], fj))
+		if adaptive {
+			errDY = md3.Add(errDY, md3.Scale(h*(dp54b[j]-dp54bstar[j]), fj))
+			errY = md3.Add(errY, md3.Scale(h2*(grkn54b[j]-grkn54bstar[j]), fj))
+		}
+	}
+	if adaptive {
+		errMax := math.Max(math.Abs(errY.X)+math.Abs(errY.Y)+math.Abs(errY.Z),
+			math.Abs(errDY.X)+math.Abs(errDY.Y)+math.Abs(errDY.Z))
+		hnew := rk.maxStep
+		if errMax > 0 {
+			hnew = rk.relax * h * math.Pow(rk.atol/errMax, 0.2)
+		}
+		hnew = math.Min(math.Max(hnew, rk.minStep), rk.maxStep)
+		if errMax > rk.atol && h > rk.minStep {
+			// Error is not permissible and we may redo the step.
+			h = hnew
+			goto SOLVE
+		}
+		// The error is within tolerance and we may suggest the user use a larger step.
+		hnext = hnew
+	}
+	if rk.jac != nil {
+		for k := range rk.vy {
+			var hFb, hFbp md3.Mat3
+			for j := range rk.vf {
+				hFb = md3.AddMat3(hFb, md3.ScaleMat3(rk.vf[j][k], h*grkn54b[j]))
+				hFbp = md3.AddMat3(hFbp, md3.ScaleMat3(rk.vf[j][k], h*dp54b[j]))
+			}
+			rk.vy[k] = md3.AddMat3(rk.vy[k], md3.ScaleMat3(md3.AddMat3(rk.vdy[k], hFb), h))
+			rk.vdy[k] = md3.AddMat3(rk.vdy[k], hFbp)
+		}
+	}
+	// y[i+1] = y[i] + h*(dy[i] + h*sum(bbar*F))
+	// dy[i+1] = dy[i] + h*sum(b*F)
+	rk.y = md3.Add(y, md3.Scale(h, md3.Add(dy, hFb)))
+	rk.dy = md3.Add(dy, hFbp)
+	rk.dom += h
+	return hnext, nil
+}
+
+// Dormand-Prince 5(4) tableau.
+var (
+	dp54c = [grkn54Len]float64{0, 1. / 5, 3. / 10, 4. / 5, 8. / 9, 1, 1}
+	dp54A = [grkn54Len][grkn54Len]float64{
+		1: {1. / 5},
+		2: {3. / 40, 9. / 40},
+		3: {44. / 45, -56. / 15, 32. / 9},
+		4: {19372. / 6561, -25360. / 2187, 64448. / 6561, -212. / 729},
+		5: {9017. / 3168, -355. / 33, 46732. / 5247, 49. / 176, -5103. / 18656},
+		6: {35. / 384, 0, 500. / 1113, 125. / 192, -2187. / 6784, 11. / 84},
+	}
+	// 5th order weights.
+	dp54b = [grkn54Len]float64{35. / 384, 0, 500. / 1113, 125. / 192, -2187. / 6784, 11. / 84, 0}
+	// 4th order weights for error estimation.
+	dp54bstar = [grkn54Len]float64{5179. / 57600, 0, 7571. / 16695, 393. / 640, -92097. / 339200, 187. / 2100, 1. / 40}
+
+	// Nyström position coefficients derived from the Runge-Kutta tableau
+	// applied to the first order system (y, y')' = (y', f):
+	//  Abar = A*A, bbar = b*A
+	grkn54A              = squareTableau(dp54A)
+	grkn54b, grkn54bstar = mulWeights(dp54b, dp54A), mulWeights(dp54bstar, dp54A)
+)
+
+// IdentityVariational returns the partial derivatives of the initial conditions with respect to themselves,
+// which are the initial conditions of the variational equations.
+func IdentityVariational() (Y, DY [2]md3.Mat3) {
+	Y[0], DY[1] = md3.IdentityMat3(), md3.IdentityMat3()
+	return Y, DY
+}
+
+func squareTableau(a [grkn54Len][grkn54Len]float64) (a2 [grkn54Len][grkn54Len]float64) {
+	for i := range a {
+		for j := range a {
+			for k := range a {
+				a2[i][j] += a[i][k] * a[k][j]
+			}
+		}
+	}
+	return a2
+}
+
+func mulWeights(b [grkn54Len]float64, a [grkn54Len][grkn54Len]float64) (ba [grkn54Len]float64) {
+	for j := range b {
+		for k := range b {
+			ba[j] += b[k] * a[k][j]
+		}
+	}
+	return ba
+}
@@ -0,0 +1,142 @@
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestGRKN54DampedOscillator(t *testing.T) {
+	// y'' = -w^2*y - 2*zeta*w*y' with y(0)=1, y'(0)=0.
+	const w, zeta = 2.0, 0.1
+	wd := w * math.Sqrt(1-zeta*zeta)
+	exact := func(t float64) float64 {
+		return math.Exp(-zeta*w*t) * (math.Cos(wd*t) + zeta*w/wd*math.Sin(wd*t))
+	}
+	fx := func(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec) {
+		for i := range yppDst {
+			yppDst[i] = md3.Sub(md3.Scale(-w*w, yv[i]), md3.Scale(2*zeta*w, dyv[i]))
+		}
+	}
+	var lastErr float64
+	for _, h := range []float64{0.1, 0.05} {
+		rk := NewGRKN54(DefaultRelaxFactor, Parameters{})
+		rk.Init(IVP2General{Y0: md3.Vec{X: 1}, Func: fx})
+		const tEnd = 10.
+		for i := 0; i < int(tEnd/h+0.5); i++ {
+			rk.Step(h)
+		}
+		tf, y, _ := rk.State()
+		err := math.Abs(y.X - exact(tf))
+		if err > 1e-5 {
+			t.Errorf("h=%g: error too large %g", h, err)
+		}
+		if lastErr != 0 && lastErr/err < 16 {
+			// Halving step of a 5th order method should reduce error by ~32.
+			t.Errorf("h=%g: unexpected convergence ratio %g", h, lastErr/err)
+		}
+		lastErr = err
+	}
+
+	// Adaptive stepping keeps error within tolerance.
+	rk := NewGRKN54(DefaultRelaxFactor, Parameters{AbsTolerance: 1e-9, MinStep: 1e-6, MaxStep: 1})
+	rk.Init(IVP2General{Y0: md3.Vec{X: 1}, Func: fx})
+	h := 0.5
+	for tf, _, _ := rk.State(); tf < 10; tf, _, _ = rk.State() {
+		h, _ = rk.Step(math.Min(h, 10-tf))
+	}
+	tf, y, _ := rk.State()
+	if err := math.Abs(y.X - exact(tf)); err > 1e-6 {
+		t.Errorf("adaptive: error too large %g", err)
+	}
+}
+
+func TestVariationalOscillator(t *testing.T) {
+	// For the linear system y'' = -w^2*y - 2*zeta*w*y' the partial derivatives with respect to the
+	// initial conditions are the solutions starting at y(0)=1, y'(0)=0 and y(0)=0, y'(0)=1.
+	const w, zeta, h = 2.0, 0.1, 0.01
+	wd := w * math.Sqrt(1-zeta*zeta)
+	dydy0 := func(t float64) float64 {
+		return math.Exp(-zeta*w*t) * (math.Cos(wd*t) + zeta*w/wd*math.Sin(wd*t))
+	}
+	dydv0 := func(t float64) float64 { return math.Exp(-zeta*w*t) * math.Sin(wd*t) / wd }
+	Jy := md3.ScaleMat3(md3.IdentityMat3(), -w*w)
+	Jdy := md3.ScaleMat3(md3.IdentityMat3(), -2*zeta*w)
+	rk := NewGRKN54(DefaultRelaxFactor, Parameters{})
+	rk.Init(IVP2General{
+		Y0: md3.Vec{X: 1, Y: -2, Z: 3},
+		Func: func(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec) {
+			for i := range yppDst {
+				yppDst[i] = md3.Add(md3.MulMatVec(Jy, yv[i]), md3.MulMatVec(Jdy, dyv[i]))
+			}
+		},
+		Jac: func(t float64, y, dy md3.Vec) (md3.Mat3, md3.Mat3) { return Jy, Jdy },
+	})
+	for i := 0; i < 1000; i++ {
+		rk.Step(h)
+	}
+	Y, _ := rk.Variational()
+	tf, _, _ := rk.State()
+	if d := Y[0].VecDiag().X - dydy0(tf); math.Abs(d) > 1e-8 {
+		t.Errorf("GRKN54 ∂y/∂y0 error %g", d)
+	}
+	if d := Y[1].VecDiag().Z - dydv0(tf); math.Abs(d) > 1e-8 {
+		t.Errorf("GRKN54 ∂y/∂dy0 error %g", d)
+	}
+
+	// Undamped oscillator with RKN1210.
+	rkn := NewRKN1210(DefaultRelaxFactor, DefaultPreconditioner, Parameters{})
+	rkn.Init(IVP2{
+		Y0: md3.Vec{X: 1},
+		Func: func(yppDst []md3.Vec, tv []float64, yv []md3.Vec) {
+			for i := range yppDst {
+				yppDst[i] = md3.MulMatVec(Jy, yv[i])
+			}
+		},
+		Jac: func(t float64, y md3.Vec) md3.Mat3 { return Jy },
+	})
+	for i := 0; i < 100; i++ {
+		rkn.Step(0.1)
+	}
+	Y, DY := rkn.Variational()
+	tf, _, _ = rkn.State()
+	s, c := math.Sincos(w * tf)
+	want := [4]float64{c, s / w, -w * s, c}
+	got := [4]float64{Y[0].VecDiag().Y, Y[1].VecDiag().Y, DY[0].VecDiag().Y, DY[1].VecDiag().Y}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-10 {
+			t.Errorf("RKN1210 variational element %d want %g, got %g", i, want[i], got[i])
+		}
+	}
+}
+
+func TestAdaptiveStepSuggestion(t *testing.T) {
+	// A small step on a smooth problem with loose tolerance suggests a larger next step.
+	const h, maxStep = 1e-3, 1.
+	params := Parameters{AbsTolerance: 1e-6, MinStep: 1e-9, MaxStep: maxStep}
+	rk := NewGRKN54(DefaultRelaxFactor, params)
+	rk.Init(IVP2General{Y0: md3.Vec{X: 1}, Func: func(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec) {
+		for i := range yppDst {
+			yppDst[i] = md3.Scale(-1, yv[i])
+		}
+	}})
+	if hnext, _ := rk.Step(h); !(hnext > h) || hnext > maxStep {
+		t.Errorf("GRKN54 suggested step %g, want in (%g, %g]", hnext, h, maxStep)
+	}
+	rkn := NewRKN1210(DefaultRelaxFactor, DefaultPreconditioner, params)
+	rkn.Init(IVP2{Y0: md3.Vec{X: 1}, Func: func(yppDst []md3.Vec, tv []float64, yv []md3.Vec) {
+		for i := range yppDst {
+			yppDst[i] = md3.Scale(-1, yv[i])
+		}
+	}})
+	if hnext, _ := rkn.Step(h); !(hnext > h) || hnext > maxStep {
+		t.Errorf("RKN1210 suggested step %g, want in (%g, %g]", hnext, h, maxStep)
+	}
+	// Fixed step integration returns the step taken.
+	fixed := NewGRKN54(DefaultRelaxFactor, Parameters{})
+	fixed.Init(IVP2General{Y0: md3.Vec{X: 1}, Func: func(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec) {}})
+	if hnext, _ := fixed.Step(h); hnext != h {
+		t.Errorf("fixed step returned %g, want %g", hnext, h)
+	}
+}
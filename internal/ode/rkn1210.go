@@ -28,6 +28,9 @@ type IVP2 struct {
 	// The function call is vectorised such that len(yppDst)==len(t)==len(y)
 	// and after Func call ends yppDst must have evaluation of second order derivative of solution.
 	Func func(yppDst []md3.Vec, tv []float64, yv []md3.Vec)
+	// Jac is optional and returns the partial derivatives of Func with respect to y at (t, y).
+	// If set the variational equations are integrated alongside the solution, see [RKN1210.Variational].
+	Jac func(t float64, y md3.Vec) md3.Mat3
 }
 
 // RKN1210 is a Runge-Kutta-Nyström 12(10) integration scheme implementation for second-order differential equation systems.
@@ -44,6 +47,10 @@ type RKN1210 struct {
 	// Step control.
 	atol, minStep, maxStep float64
 	fx                     func(yppDst []md3.Vec, tv []float64, yv []md3.Vec)
+	// Variational equations: partial derivatives of y and dy with respect to y0 and dy0.
+	jac     func(t float64, y md3.Vec) md3.Mat3
+	vy, vdy [2]md3.Mat3
+	vf      [rk1210Len][2]md3.Mat3
 }
 
 func NewRKN1210(relax, preConditioner float64, cfg Parameters) *RKN1210 {
@@ -67,6 +74,8 @@ func NewRKN1210(relax, preConditioner float64, cfg Parameters) *RKN1210 {
 func (rk *RKN1210) Init(ivp IVP2) {
 	rk.reset()
 	rk.fx = ivp.Func
+	rk.jac = ivp.Jac
+	rk.SetVariational(IdentityVariational())
 	// Initial value problem definition.
 	rk.dom, rk.y = ivp.T0, ivp.Y0
 	rk.dy = ivp.DY0
@@ -80,6 +89,21 @@ func (rk *RKN1210) SetState(t float64, y, dy md3.Vec) {
 	rk.dom, rk.y, rk.dy = t, y, dy
 }
 
+// Variational returns the partial derivatives of the solution with respect to the initial conditions:
+//
+//	Y = [∂y/∂y0, ∂y/∂dy0]
+//	DY = [∂dy/∂y0, ∂dy/∂dy0]
+//
+// They are only integrated if the initial value problem's Jac is set.
+func (rk *RKN1210) Variational() (Y, DY [2]md3.Mat3) {
+	return rk.vy, rk.vdy
+}
+
+// SetVariational sets the partial derivatives of the solution with respect to the initial conditions.
+func (rk *RKN1210) SetVariational(Y, DY [2]md3.Mat3) {
+	rk.vy, rk.vdy = Y, DY
+}
+
 func (rk *RKN1210) reset() {
 	*rk = RKN1210{
 		precond: rk.precond,
@@ -90,8 +114,11 @@ func (rk *RKN1210) reset() {
 	}
 }
 
+// Step advances the solution by h and returns the step to use next, which differs from h
+// only when adaptive stepping is enabled.
 func (rk *RKN1210) Step(h float64) (float64, error) {
 	adaptive := rk.atol > 0
+	hnext := h
 	var aux md3.Vec
 	y := rk.y
 	dy := rk.dy
@@ -118,10 +145,21 @@ SOLVE:
 		}
 		yv[j] = aux
 		tv[j] = t + hc
+		// Stages depend on the evaluation of all previous stages so they are evaluated in sequence.
+		fun(F[j:j+1], tv[j:j+1], yv[j:j+1])
+		if rk.jac != nil {
+			// Stage derivatives of the variational equations Y'' = J*Y.
+			J := rk.jac(tv[j], yv[j])
+			for k := range rk.vy {
+				Y := md3.AddMat3(rk.vy[k], md3.ScaleMat3(rk.vdy[k], hc))
+				for iF := 0; iF < j; iF++ {
+					Y = md3.AddMat3(Y, md3.ScaleMat3(rk.vf[iF][k], h2*rkn12A[j][iF]))
+				}
+				rk.vf[j][k] = md3.MulMat3(J, Y)
+			}
+		}
 	}
 
-	fun(F[:], tv[:], yv[:])
-
 	for j := range F {
 		// finally F[:,j] = Func( aux ) @ t+h*c[j]
 		fj := F[j]
@@ -151,18 +189,28 @@ SOLVE:
 			goto SOLVE
 		}
 		// The error is within tolerance and we may suggest the user use a larger step.
-		// Modify return value to suggest new step.
-		defer func() { h = hnew }()
+		hnext = hnew
 	}
 
 	// calculate next step solutions with high order B's:
 	//  y[i+1] = y[i] + h*(dy[i] + hFbhat)
 	//  dy[i+1] = dy[i] + hFDbhat
+	if rk.jac != nil {
+		for k := range rk.vy {
+			var hFb, hFDb md3.Mat3
+			for j := range rk.vf {
+				hFb = md3.AddMat3(hFb, md3.ScaleMat3(rk.vf[j][k], h*rkn12bhat[j]))
+				hFDb = md3.AddMat3(hFDb, md3.ScaleMat3(rk.vf[j][k], h*rkn12bphat[j]))
+			}
+			rk.vy[k] = md3.AddMat3(rk.vy[k], md3.ScaleMat3(md3.AddMat3(rk.vdy[k], hFb), h))
+			rk.vdy[k] = md3.AddMat3(rk.vdy[k], hFDb)
+		}
+	}
 	aux = md3.Add(dy, rk.hFbhat)
 	rk.y = md3.Add(rk.y, md3.Scale(h, aux))
 	rk.dy = md3.Add(rk.dy, rk.hFDbhat)
 	rk.dom += h
-	return h, nil
+	return hnext, nil
 }
 
 var (
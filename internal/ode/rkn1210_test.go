@@ -0,0 +1,26 @@
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestRKN1210Oscillator(t *testing.T) {
+	// y'' = -y with y(0)=1, y'(0)=0 has solution y(t) = cos(t).
+	rk := NewRKN1210(DefaultRelaxFactor, DefaultPreconditioner, Parameters{})
+	rk.Init(IVP2{Y0: md3.Vec{X: 1}, Func: func(yppDst []md3.Vec, tv []float64, yv []md3.Vec) {
+		for i := range yppDst {
+			yppDst[i] = md3.Scale(-1, yv[i])
+		}
+	}})
+	const h = 0.5
+	for i := 0; i < 20; i++ {
+		rk.Step(h)
+	}
+	tf, y, dy := rk.State()
+	if err := math.Hypot(y.X-math.Cos(tf), dy.X+math.Sin(tf)); err > 1e-10 {
+		t.Errorf("error too large %g", err)
+	}
+}
@@ -0,0 +1,140 @@
+package navigation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/internal/linalg"
+)
+
+// Observation is the measured value Z of measurement model Model at epoch time T [s].
+type Observation struct {
+	T     float64
+	Model Measurement
+	Z     []float64
+}
+
+// BatchSolution is the state estimated by [BatchLeastSquares].
+type BatchSolution struct {
+	T        float64 // Epoch time of the estimate [s].
+	SBI, VBI md3.Vec // Estimated inertial position [m] and velocity [m/s].
+	// Covariance of the estimated state, position components first.
+	Covariance [StateDim][StateDim]float64
+	// RMS is the root mean square of the measurement residuals normalized by their noise [Adim].
+	RMS        float64
+	Iterations int
+}
+
+// BatchLeastSquares estimates the state of a body at an epoch from a batch of observations by differential
+// correction. Every iteration propagates the estimate through all observations with the integrator and its
+// state transition matrix and solves the weighted normal equations for the correction of the initial state.
+type BatchLeastSquares struct {
+	// MaxStep is the largest integrator step used to propagate the state [s].
+	MaxStep float64
+	// MaxIterations is the maximum number of differential corrections.
+	MaxIterations int
+	// Tolerance is the position correction [m] below which the solution has converged.
+	Tolerance float64
+	phys      *gnco.PhysicsPointIntegrator
+}
+
+// NewBatchLeastSquares returns a batch least squares estimator using phys as process model.
+// The estimator takes ownership of phys' state.
+func NewBatchLeastSquares(phys *gnco.PhysicsPointIntegrator) *BatchLeastSquares {
+	return &BatchLeastSquares{MaxStep: 10, MaxIterations: 20, Tolerance: 1e-3, phys: phys}
+}
+
+// Solve returns the state at epoch time t0 that best fits the observations starting from the initial guess
+// SBI0, VBI0, which may come from initial orbit determination such as Gauss' method of package orbits. An error is returned if
+// an observation does not match the dimension of its model, the observations do not determine the state
+// or the corrections do not converge within MaxIterations.
+func (b *BatchLeastSquares) Solve(t0 float64, SBI0, VBI0 md3.Vec, obs []Observation) (BatchSolution, error) {
+	obs = slices.Clone(obs)
+	slices.SortStableFunc(obs, func(a, b Observation) int {
+		switch {
+		case a.T < b.T:
+			return -1
+		case a.T > b.T:
+			return 1
+		}
+		return 0
+	})
+	noise := make([]*linalg.Dense, len(obs))
+	for i, o := range obs {
+		R, err := noiseCovariance(o.Model, o.Z)
+		if err != nil {
+			return BatchSolution{}, fmt.Errorf("observation %d: %w", i, err)
+		}
+		noise[i] = R
+	}
+	x := stateVec(SBI0, VBI0)
+	for iter := 1; iter <= b.MaxIterations; iter++ {
+		normal, rhs, rms := b.accumulate(t0, x, obs, noise)
+		cov, err := linalg.Inverse(normal)
+		if err != nil {
+			return BatchSolution{}, errors.New("observations do not determine the state")
+		}
+		dx := linalg.MulVec(cov, rhs)
+		for i := range x {
+			x[i] += dx[i]
+		}
+		if math.Sqrt(dx[0]*dx[0]+dx[1]*dx[1]+dx[2]*dx[2]) < b.Tolerance {
+			// Residuals and covariance at the corrected state.
+			normal, _, rms = b.accumulate(t0, x, obs, noise)
+			if cov, err = linalg.Inverse(normal); err != nil {
+				return BatchSolution{}, errors.New("observations do not determine the state")
+			}
+			cov.Symmetrize()
+			sol := BatchSolution{T: t0, RMS: rms, Iterations: iter}
+			sol.SBI, sol.VBI = splitState(x)
+			for i := range sol.Covariance {
+				copy(sol.Covariance[i][:], cov.Row(i))
+			}
+			return sol, nil
+		}
+	}
+	return BatchSolution{}, errors.New("batch least squares did not converge")
+}
+
+// accumulate propagates state x at epoch time t0 through the observations and returns the normal matrix HᵀR⁻¹H,
+// the right hand side HᵀR⁻¹y of the normal equations with residuals y and the normalized RMS of the residuals.
+func (b *BatchLeastSquares) accumulate(t0 float64, x [StateDim]float64, obs []Observation, noise []*linalg.Dense) (normal *linalg.Dense, rhs []float64, rms float64) {
+	normal = linalg.New(StateDim, StateDim)
+	rhs = make([]float64, StateDim)
+	SBI, VBI := splitState(x)
+	b.phys.SetState(t0, SBI, VBI)
+	b.phys.ResetSTM()
+	Phi := linalg.New(StateDim, StateDim)
+	var n int
+	for i, o := range obs {
+		SBI, VBI := b.phys.Propagate(o.T, b.MaxStep)
+		stm := b.phys.STM()
+		for j := range stm {
+			copy(Phi.Row(j), stm[j][:])
+		}
+		xi := stateVec(SBI, VBI)
+		zhat := o.Model.Predict(o.T, SBI, VBI)
+		y := residual(o.Model, o.Z, zhat)
+		H := linalg.Mul(jacobian(o.Model, o.T, xi, zhat), Phi)
+		// Noise covariance is diagonal.
+		for k := range y {
+			w := 1 / noise[i].At(k, k)
+			rms += y[k] * y[k] * w
+			n++
+			for r := 0; r < StateDim; r++ {
+				rhs[r] += H.At(k, r) * w * y[k]
+				for c := 0; c < StateDim; c++ {
+					normal.Set(r, c, normal.At(r, c)+H.At(k, r)*w*H.At(k, c))
+				}
+			}
+		}
+	}
+	if n > 0 {
+		rms = math.Sqrt(rms / float64(n))
+	}
+	return normal, rhs, rms
+}
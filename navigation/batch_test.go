@@ -0,0 +1,59 @@
+package navigation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestBatchLeastSquares(t *testing.T) {
+	station, truth := trackingScenario(t)
+	_, SBI0, VBI0 := truth.State()
+	models := []Measurement{
+		Range{Station: station, Sigma: 10},
+		RangeRate{Station: station, Sigma: 0.01},
+		AzEl{Station: station, SigmaAz: 0.01 * deg, SigmaEl: 0.01 * deg},
+	}
+	rng := rand.New(rand.NewSource(3))
+	var obs []Observation
+	for tm := 600.; tm >= 10; tm -= 10 { // Order of observations is irrelevant.
+		SBI, VBI := truth.Propagate(tm, 10)
+		if el := (AzEl{Station: station}).Predict(tm, SBI, VBI)[1]; el < 5*deg {
+			continue
+		}
+		for _, m := range models {
+			z := m.Predict(tm, SBI, VBI)
+			for i, sigma := range m.Noise() {
+				z[i] += sigma * rng.NormFloat64()
+			}
+			obs = append(obs, Observation{T: tm, Model: m, Z: z})
+		}
+	}
+	bls := NewBatchLeastSquares(filterPhys(truth, md3.Vec{}, md3.Vec{}))
+	guessPos, guessVel := md3.Add(SBI0, md3.Vec{X: 20e3, Y: -10e3, Z: 5e3}), md3.Add(VBI0, md3.Vec{X: 10, Z: -10})
+	sol, err := bls.Solve(0, guessPos, guessVel, obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errPos := md3.Norm(md3.Sub(sol.SBI, SBI0))
+	errVel := md3.Norm(md3.Sub(sol.VBI, VBI0))
+	sigmaPos := math.Sqrt(sol.Covariance[0][0] + sol.Covariance[1][1] + sol.Covariance[2][2])
+	t.Logf("iterations=%d rms=%.3f position error %.2fm (1σ %.2fm) velocity error %.4fm/s", sol.Iterations, sol.RMS, errPos, sigmaPos, errVel)
+	// The epoch precedes the pass so its estimate is less accurate than the filters' at the end of the pass.
+	if errPos > 150 || errVel > 0.5 {
+		t.Errorf("estimate error too large: %gm, %gm/s", errPos, errVel)
+	}
+	if errPos > 3*sigmaPos {
+		t.Errorf("position error %gm inconsistent with covariance 1σ %gm", errPos, sigmaPos)
+	}
+	if sol.RMS < 0.5 || sol.RMS > 1.5 {
+		t.Errorf("normalized residual RMS %g inconsistent with measurement noise", sol.RMS)
+	}
+
+	// A single observation does not determine the state.
+	if _, err := bls.Solve(0, guessPos, guessVel, obs[:1]); err == nil {
+		t.Error("expected error for underdetermined batch")
+	}
+}
@@ -0,0 +1,91 @@
+package navigation
+
+import (
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/internal/linalg"
+)
+
+// EKF is an extended Kalman filter estimating the inertial position and velocity of a body. The state
+// is propagated with a [gnco.PhysicsPointIntegrator] and the covariance is propagated with the state
+// transition matrix of the trajectory. Measurement models are linearized about the estimate.
+type EKF struct {
+	// MaxStep is the largest integrator step used to propagate the state [s].
+	MaxStep float64
+	// ProcessNoise is the power spectral density of the unmodeled acceleration [m^2.s^-3].
+	ProcessNoise float64
+	filter
+}
+
+// NewEKF returns an extended Kalman filter using phys as process model. The initial estimate is
+// the current state of phys with covariance P0. The filter takes ownership of phys' state.
+func NewEKF(phys *gnco.PhysicsPointIntegrator, P0 [StateDim][StateDim]float64) *EKF {
+	return &EKF{MaxStep: 10, filter: newFilter(phys, P0)}
+}
+
+// Predict propagates the estimate and its covariance to epoch time t.
+func (k *EKF) Predict(t float64) {
+	if t == k.t {
+		return
+	}
+	x, Phi := k.transition(t, k.MaxStep)
+	P := linalg.Add(linalg.Mul(linalg.Mul(Phi, k.P), Phi.T()), 1, processNoise(k.ProcessNoise, t-k.t))
+	P.Symmetrize()
+	k.t, k.x, k.P = t, x, P
+}
+
+// Update propagates the estimate to epoch time t and corrects it with the observation z of measurement m.
+// An error is returned if z does not match the dimension of m or the innovation covariance is singular,
+// in which case the estimate is left propagated but uncorrected.
+func (k *EKF) Update(t float64, m Measurement, z []float64) error {
+	R, err := noiseCovariance(m, z)
+	if err != nil {
+		return err
+	}
+	k.Predict(t)
+	SBI, VBI := splitState(k.x)
+	zhat := m.Predict(t, SBI, VBI)
+	H := jacobian(m, t, k.x, zhat)
+	PHt := linalg.Mul(k.P, H.T())
+	S := linalg.Add(linalg.Mul(H, PHt), 1, R)
+	Sinv, err := linalg.Inverse(S)
+	if err != nil {
+		return err
+	}
+	K := linalg.Mul(PHt, Sinv)
+	dx := linalg.MulVec(K, residual(m, z, zhat))
+	for i := range k.x {
+		k.x[i] += dx[i]
+	}
+	// Joseph form keeps the covariance positive definite.
+	IKH := linalg.Add(linalg.Identity(StateDim), -1, linalg.Mul(K, H))
+	P := linalg.Mul(linalg.Mul(IKH, k.P), IKH.T())
+	P = linalg.Add(P, 1, linalg.Mul(linalg.Mul(K, R), K.T()))
+	P.Symmetrize()
+	k.P = P
+	return nil
+}
+
+// jacobian returns the partial derivatives of measurement m with respect to the state
+// at x approximated by central differences. zhat is the measurement predicted at x.
+func jacobian(m Measurement, t float64, x [StateDim]float64, zhat []float64) *linalg.Dense {
+	H := linalg.New(len(zhat), StateDim)
+	for j := 0; j < StateDim; j++ {
+		h := 1e-3 // Position perturbation [m].
+		if j >= 3 {
+			h = 1e-6 // Velocity perturbation [m/s].
+		}
+		xp, xm := x, x
+		xp[j] += h
+		xm[j] -= h
+		dz := residual(m, predict(m, t, xp), predict(m, t, xm))
+		for i := range dz {
+			H.Set(i, j, dz[i]/(2*h))
+		}
+	}
+	return H
+}
+
+func predict(m Measurement, t float64, x [StateDim]float64) []float64 {
+	SBI, VBI := splitState(x)
+	return m.Predict(t, SBI, VBI)
+}
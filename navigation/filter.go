@@ -0,0 +1,121 @@
+// Package navigation implements state estimation of a point body from noisy observations
+// using the dynamics of a [gnco.PhysicsPointIntegrator] as process model.
+package navigation
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/internal/linalg"
+)
+
+// StateDim is the dimension of the estimated state: inertial position and velocity.
+const StateDim = 6
+
+// filter is the estimate and process model shared by the Kalman filters.
+type filter struct {
+	phys *gnco.PhysicsPointIntegrator
+	t    float64
+	x    [StateDim]float64
+	P    *linalg.Dense
+}
+
+func newFilter(phys *gnco.PhysicsPointIntegrator, P0 [StateDim][StateDim]float64) filter {
+	t, SBI, VBI := phys.State()
+	f := filter{phys: phys, t: t, x: stateVec(SBI, VBI), P: linalg.New(StateDim, StateDim)}
+	f.SetCovariance(P0)
+	return f
+}
+
+// State returns the epoch time [s] and estimated inertial position [m] and velocity [m/s].
+func (f *filter) State() (t float64, SBI, VBI md3.Vec) {
+	SBI, VBI = splitState(f.x)
+	return f.t, SBI, VBI
+}
+
+// SetState sets the epoch time [s] and estimated inertial position [m] and velocity [m/s].
+func (f *filter) SetState(t float64, SBI, VBI md3.Vec) {
+	f.t, f.x = t, stateVec(SBI, VBI)
+}
+
+// Covariance returns the covariance of the estimated state. Position
+// components come first in [m^2] followed by velocity components in [m^2.s^-2].
+func (f *filter) Covariance() (P [StateDim][StateDim]float64) {
+	for i := range P {
+		copy(P[i][:], f.P.Row(i))
+	}
+	return P
+}
+
+// SetCovariance sets the covariance of the estimated state.
+func (f *filter) SetCovariance(P [StateDim][StateDim]float64) {
+	for i := range P {
+		copy(f.P.Row(i), P[i][:])
+	}
+}
+
+// propagate returns state x0 at epoch time t0 propagated to epoch time t.
+func (f *filter) propagate(x0 [StateDim]float64, t0, t, maxStep float64) [StateDim]float64 {
+	SBI, VBI := splitState(x0)
+	f.phys.SetState(t0, SBI, VBI)
+	SBI, VBI = f.phys.Propagate(t, maxStep)
+	return stateVec(SBI, VBI)
+}
+
+// transition propagates the estimate to epoch time t and returns the state transition
+// matrix of the step obtained from the integrator's variational equations.
+func (f *filter) transition(t, maxStep float64) (x [StateDim]float64, Phi *linalg.Dense) {
+	SBI, VBI := splitState(f.x)
+	f.phys.SetState(f.t, SBI, VBI)
+	f.phys.ResetSTM()
+	SBI, VBI = f.phys.Propagate(t, maxStep)
+	stm := f.phys.STM()
+	Phi = linalg.New(StateDim, StateDim)
+	for i := range stm {
+		copy(Phi.Row(i), stm[i][:])
+	}
+	return stateVec(SBI, VBI), Phi
+}
+
+// processNoise returns the covariance of the state error accumulated over dt [s] by a white noise
+// acceleration of power spectral density q [m^2.s^-3] acting independently on each axis.
+func processNoise(q, dt float64) *linalg.Dense {
+	Q := linalg.New(StateDim, StateDim)
+	if q == 0 {
+		return Q
+	}
+	dt = math.Abs(dt)
+	for i := 0; i < 3; i++ {
+		Q.Set(i, i, q*dt*dt*dt/3)
+		Q.Set(i, i+3, q*dt*dt/2)
+		Q.Set(i+3, i, q*dt*dt/2)
+		Q.Set(i+3, i+3, q*dt)
+	}
+	return Q
+}
+
+// noiseCovariance returns the diagonal measurement covariance of m and checks z's dimension.
+func noiseCovariance(m Measurement, z []float64) (*linalg.Dense, error) {
+	sigma := m.Noise()
+	if len(z) != len(sigma) {
+		return nil, errors.New("measurement dimension mismatch")
+	}
+	R := linalg.New(len(sigma), len(sigma))
+	for i, s := range sigma {
+		if !(s > 0) {
+			return nil, errors.New("measurement noise must be positive")
+		}
+		R.Set(i, i, s*s)
+	}
+	return R, nil
+}
+
+func stateVec(SBI, VBI md3.Vec) [StateDim]float64 {
+	return [StateDim]float64{SBI.X, SBI.Y, SBI.Z, VBI.X, VBI.Y, VBI.Z}
+}
+
+func splitState(x [StateDim]float64) (SBI, VBI md3.Vec) {
+	return md3.Vec{X: x[0], Y: x[1], Z: x[2]}, md3.Vec{X: x[3], Y: x[4], Z: x[5]}
+}
@@ -0,0 +1,157 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+// INS is a strapdown inertial navigation system. It integrates the specific force and angular rate
+// measured by an [IMU] in body frame into attitude, velocity and position using the local level
+// geographic (North-East-Down) mechanization: velocity relative to the world is integrated in
+// geographic frame accounting for gravity, Earth rotation (Coriolis and centrifugal accelerations)
+// and transport rate of the geographic frame over the curved surface of the world.
+// Transport rate is singular at the poles where the mechanization is not valid.
+// See Titterton, Weston - Strapdown Inertial Navigation Technology (3.7).
+type INS struct {
+	t      float64
+	posE   md3.Vec // Position in earth fixed frame.
+	coords gnco.GeocentricCoords
+	// gravity evaluates the gravity model at the position.
+	gravity gnco.Coordinates
+	VBEG    md3.Vec  // Velocity relative to earth in geographic frame.
+	TBG     md3.Mat3 // Attitude: body wrt geographic coordinates.
+	// IMU outputs at t used for trapezoidal integration.
+	f, w   md3.Vec
+	primed bool
+}
+
+// NewINS returns an INS aligned at epoch time t0 at position coords with velocity relative to the
+// world VBEG in geographic frame [m/s] and attitude TBG, the rotation tensor of body wrt geographic
+// coordinates. Gravity is given by the AGravG method of gravity which is set to the INS position at
+// every evaluation. Use [gnco.GeodesicCoords] to include the J2 term.
+func NewINS(gravity gnco.Coordinates, t0 float64, coords gnco.GeocentricCoords, VBEG md3.Vec, TBG md3.Mat3) *INS {
+	return &INS{
+		t:       t0,
+		posE:    coords.EarthFixedCoords(),
+		coords:  coords,
+		gravity: gravity,
+		VBEG:    VBEG,
+		TBG:     TBG,
+	}
+}
+
+// Update integrates the navigation solution up to epoch time t with the specific force fB [m.s^-2] and
+// angular rate wB [rad/s] of the body with respect to inertial space in body frame measured at t.
+// Measurements are interpolated linearly from those of the previous update.
+func (ins *INS) Update(t float64, fB, wB md3.Vec) {
+	if !ins.primed {
+		ins.f, ins.w, ins.primed = fB, wB, true
+	}
+	dt := t - ins.t
+	if dt == 0 {
+		ins.f, ins.w = fB, wB
+		return
+	}
+	// Body rotation over the interval including second order coning correction.
+	phiB := md3.Add(md3.Scale(dt/2, md3.Add(ins.w, wB)), md3.Scale(dt*dt/12, md3.Cross(ins.w, wB)))
+
+	// Heun's method: Euler predictor followed by trapezoidal corrector.
+	SBE0, V0, TBG0 := ins.posE, ins.VBEG, ins.TBG
+	dS0, dV0, wGI0 := ins.derivatives(SBE0, V0, TBG0, ins.f)
+	SBE1, V1 := md3.Add(SBE0, md3.Scale(dt, dS0)), md3.Add(V0, md3.Scale(dt, dV0))
+	TBG1 := rotateAttitude(TBG0, phiB, md3.Scale(dt, wGI0))
+	dS1, dV1, wGI1 := ins.derivatives(SBE1, V1, TBG1, fB)
+
+	ins.posE = md3.Add(SBE0, md3.Scale(dt/2, md3.Add(dS0, dS1)))
+	ins.VBEG = md3.Add(V0, md3.Scale(dt/2, md3.Add(dV0, dV1)))
+	ins.TBG = rotateAttitude(TBG0, phiB, md3.Scale(dt/2, md3.Add(wGI0, wGI1)))
+	ins.coords = ins.coords.World().GeocentricFromEarthFixedCoords(ins.posE)
+	ins.t, ins.f, ins.w = t, fB, wB
+}
+
+// derivatives returns the rate of change of the earth fixed position and geographic velocity
+// and the angular rate of the geographic frame wrt inertial frame in geographic frame.
+func (ins *INS) derivatives(SBE, VBEG md3.Vec, TBG md3.Mat3, fB md3.Vec) (dSBE, dVBEG, wGIG md3.Vec) {
+	w := ins.coords.World()
+	coords := w.GeocentricFromEarthFixedCoords(SBE)
+	TGE := coords.TGE()
+	ins.gravity.SetFromEarthFixedCoords(SBE)
+	r := coords.Radius()
+	sinLat, cosLat := math.Sincos(coords.Lat)
+	// Earth rate and transport rate in geographic frame.
+	wEIG := md3.Vec{X: w.Rotation * cosLat, Z: -w.Rotation * sinLat}
+	wGEG := md3.Vec{X: VBEG.Y / r, Y: -VBEG.X / r, Z: -VBEG.Y * sinLat / (cosLat * r)}
+	// Centrifugal acceleration of the rotating frame.
+	SBG := md3.MulMatVec(TGE, SBE)
+	centrifugal := md3.Scale(-1, md3.Cross(wEIG, md3.Cross(wEIG, SBG)))
+	coriolis := md3.Cross(md3.Add(md3.Scale(2, wEIG), wGEG), VBEG)
+	dVBEG = md3.Add(md3.MulMatVecTrans(TBG, fB), ins.gravity.AGravG())
+	dVBEG = md3.Sub(md3.Add(dVBEG, centrifugal), coriolis)
+	return md3.MulMatVecTrans(TGE, VBEG), dVBEG, md3.Add(wEIG, wGEG)
+}
+
+// rotateAttitude returns TBG after the body rotates by phiB in body frame and the
+// geographic frame rotates by zetaG in geographic frame, both with respect to inertial space.
+func rotateAttitude(TBG md3.Mat3, phiB, zetaG md3.Vec) md3.Mat3 {
+	TBG = md3.MulMat3(rotationTensor(md3.Scale(-1, phiB)), TBG)
+	TBG = md3.MulMat3(TBG, rotationTensor(zetaG))
+	return orthonormalize(TBG)
+}
+
+// rotationTensor returns exp([θ×]) given by Rodrigues' formula.
+func rotationTensor(theta md3.Vec) md3.Mat3 {
+	angle := md3.Norm(theta)
+	K := md3.Skew(theta)
+	if angle < 1e-8 {
+		return md3.AddMat3(md3.IdentityMat3(), md3.AddMat3(K, md3.ScaleMat3(md3.MulMat3(K, K), 0.5)))
+	}
+	a := math.Sin(angle) / angle
+	b := (1 - math.Cos(angle)) / (angle * angle)
+	return md3.AddMat3(md3.IdentityMat3(), md3.AddMat3(md3.ScaleMat3(K, a), md3.ScaleMat3(md3.MulMat3(K, K), b)))
+}
+
+// orthonormalize removes the first order orthogonality error of an almost orthonormal T.
+func orthonormalize(T md3.Mat3) md3.Mat3 {
+	// T = T - T*(Tᵀ*T - I)/2
+	E := md3.SubMat3(md3.MulMat3(T.Transpose(), T), md3.IdentityMat3())
+	return md3.SubMat3(T, md3.ScaleMat3(md3.MulMat3(T, E), 0.5))
+}
+
+// Time returns the epoch time of the navigation solution [s].
+func (ins *INS) Time() float64 { return ins.t }
+
+// Position returns the geocentric coordinates of the body.
+func (ins *INS) Position() gnco.GeocentricCoords { return ins.coords }
+
+// Geodetic returns the geodetic latitude [rad], longitude [rad] and height above the reference ellipsoid [m] of the body.
+func (ins *INS) Geodetic() (lat, long, height float64) {
+	return ins.coords.World().GeodeticFromEarthFixed(ins.posE)
+}
+
+// VelocityGeographic returns the velocity of the body relative to the world in geographic frame [m/s].
+func (ins *INS) VelocityGeographic() md3.Vec { return ins.VBEG }
+
+// Attitude returns the rotation tensor of body wrt geographic coordinates.
+func (ins *INS) Attitude() md3.Mat3 { return ins.TBG }
+
+// Orientation returns the orientation of the body with the velocity frame given by the velocity relative to the world.
+func (ins *INS) Orientation() gnco.Orientation {
+	TVG := gnco.TVGFromVelocity(ins.VBEG)
+	return gnco.Orientation{
+		TBV: md3.MulMat3(ins.TBG, TVG.Transpose()),
+		TVG: TVG,
+		TGI: ins.coords.TGI(ins.t),
+	}
+}
+
+// InertialState returns the inertial position [m] and velocity [m/s] of the body.
+func (ins *INS) InertialState() (SBI, VBI md3.Vec) {
+	w := ins.coords.World()
+	TEI := w.TEI(ins.t)
+	SBI = md3.MulMatVecTrans(TEI, ins.posE)
+	VBI = md3.MulMatVecTrans(ins.coords.TGI(ins.t), ins.VBEG)
+	VBI = md3.Add(VBI, md3.Vec{X: -w.Rotation * SBI.Y, Y: w.Rotation * SBI.X})
+	return SBI, VBI
+}
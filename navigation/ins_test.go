@@ -0,0 +1,90 @@
+package navigation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+func TestINSRecoversTrajectory(t *testing.T) {
+	// Truth is a thrusting body tumbling at a constant body rate integrated in inertial frame.
+	earth := gnco.NewEarth()
+	start := earth.GeocentricFromDegrees(10, 30, 1000)
+	VBEG0 := md3.Vec{X: 100, Y: 150, Z: -50}
+	TBG0 := rotationTensor(md3.Vec{X: 0.1, Y: -1, Z: 0.3})
+	wB := md3.Vec{X: 0.05, Y: -0.02, Z: 0.1}
+	SBI0, TGI0 := start.InertialCoords(0)
+	VBI0 := md3.Add(md3.MulMatVecTrans(TGI0, VBEG0), md3.Vec{X: -earth.Rotation * SBI0.Y, Y: earth.Rotation * SBI0.X})
+	TBI0 := md3.MulMat3(TBG0, TGI0)
+	TBI := func(tm float64) md3.Mat3 { return md3.MulMat3(rotationTensor(md3.Scale(-tm, wB)), TBI0) }
+	fB := func(tm float64) md3.Vec { return md3.Vec{X: 12 + 3*math.Sin(0.2*tm), Z: 1} }
+
+	coords := start.Geodesic()
+	truth := gnco.NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	truth.AddForceModel("thrust", gnco.ForceFunc(func(s *gnco.ForceState) md3.Vec {
+		return md3.MulMatVecTrans(TBI(s.T), fB(s.T))
+	}))
+	gravity := start.Geodesic()
+	ins := NewINS(&gravity, 0, start, VBEG0, TBG0)
+	const dt, tEnd = 0.01, 100.
+	imu := NewIMU(TriadErrors{}, TriadErrors{}, dt, 1)
+	for i := 0; i <= int(tEnd/dt); i++ {
+		tm := float64(i) * dt
+		if i > 0 {
+			truth.Step(dt, md3.Vec{})
+		}
+		_, SBI, _ := truth.State()
+		// Specific force from the truth integrator's force models.
+		TGI := earth.GeocentricFromEarthFixedCoords(md3.MulMatVec(earth.TEI(tm), SBI)).TGI(tm)
+		TBG := md3.MulMat3(TBI(tm), TGI.Transpose())
+		f := SpecificForce(truth, gnco.Orientation{TBV: TBG, TVG: md3.IdentityMat3(), TGI: TGI}, md3.Vec{})
+		fm, wm, ok := imu.Measure(tm, f, wB)
+		if !ok {
+			t.Fatal("IMU not sampled")
+		}
+		ins.Update(tm, fm, wm)
+	}
+	_, SBI, VBI := truth.State()
+	SBIins, VBIins := ins.InertialState()
+	errPos := md3.Norm(md3.Sub(SBI, SBIins))
+	errVel := md3.Norm(md3.Sub(VBI, VBIins))
+	TGI := ins.Position().TGI(tEnd)
+	var errAtt float64
+	for _, v := range md3.SubMat3(ins.Attitude(), md3.MulMat3(TBI(tEnd), TGI.Transpose())).Array() {
+		errAtt = math.Max(errAtt, math.Abs(v))
+	}
+	t.Logf("distance travelled %.1fkm, position error %.3gm, velocity error %.3gm/s", md3.Norm(md3.Sub(SBI, SBI0))/1e3, errPos, errVel)
+	if errPos > 0.05 || errVel > 1e-3 || errAtt > 1e-6 {
+		t.Errorf("INS diverged from truth: position %gm, velocity %gm/s, attitude %g", errPos, errVel, errAtt)
+	}
+	// Geodetic output agrees with the truth position.
+	lat, long, h := ins.Geodetic()
+	latT, longT, hT := earth.GeodeticFromEarthFixed(md3.MulMatVec(earth.TEI(tEnd), SBI))
+	if math.Abs(lat-latT)*earth.Radius > 1 || math.Abs(long-longT)*earth.Radius > 1 || math.Abs(h-hT) > 1 {
+		t.Errorf("geodetic position mismatch: %g %g %g", lat-latT, long-longT, h-hT)
+	}
+}
+
+func TestINSStationary(t *testing.T) {
+	// A body at rest on the rotating world senses the reaction to gravity and Earth's rotation rate.
+	earth := gnco.NewEarth()
+	start := earth.GeocentricFromDegrees(-58, -34, 0)
+	gravity := start.Geodesic()
+	ins := NewINS(&gravity, 0, start, md3.Vec{}, md3.IdentityMat3())
+	TGE := start.TGE()
+	wEG := md3.MulMatVec(TGE, md3.Vec{Z: earth.Rotation})
+	SBG := md3.MulMatVec(TGE, start.EarthFixedCoords())
+	// Specific force balances gravity and centrifugal acceleration.
+	f := md3.Add(md3.Scale(-1, gravity.AGravG()), md3.Cross(wEG, md3.Cross(wEG, SBG)))
+	for tm := 0.; tm <= 3600; tm += 0.1 {
+		ins.Update(tm, f, wEG)
+	}
+	if d := md3.Norm(md3.Sub(ins.Position().EarthFixedCoords(), start.EarthFixedCoords())); d > 0.01 {
+		t.Errorf("stationary INS drifted %gm", d)
+	}
+	if v := md3.Norm(ins.VelocityGeographic()); v > 1e-5 {
+		t.Errorf("stationary INS velocity %gm/s", v)
+	}
+}
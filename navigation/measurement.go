@@ -0,0 +1,120 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+// Measurement is a model of an observation of a body's inertial state.
+type Measurement interface {
+	// Predict returns the observation expected at epoch time t [s] of a body at
+	// inertial position SBI and velocity VBI.
+	Predict(t float64, SBI, VBI md3.Vec) []float64
+	// Noise returns the standard deviation of each observation component.
+	Noise() []float64
+}
+
+// residualer is implemented by measurements with components that must be wrapped
+// when subtracting observations, such as angles.
+type residualer interface {
+	Residual(z, zhat []float64) []float64
+}
+
+var (
+	_ Measurement = Range{}
+	_ Measurement = RangeRate{}
+	_ Measurement = AzEl{}
+	_ Measurement = GPSPosition{}
+	_ residualer  = AzEl{}
+)
+
+// residual returns z - zhat wrapping components as required by m.
+func residual(m Measurement, z, zhat []float64) []float64 {
+	if r, ok := m.(residualer); ok {
+		return r.Residual(z, zhat)
+	}
+	dz := make([]float64, len(z))
+	for i := range z {
+		dz[i] = z[i] - zhat[i]
+	}
+	return dz
+}
+
+// Range is the slant range [m] from a ground station to the body.
+type Range struct {
+	Station gnco.GeocentricCoords
+	Sigma   float64 // Standard deviation of range [m].
+}
+
+// Predict returns the slant range. It implements [Measurement].
+func (r Range) Predict(t float64, SBI, VBI md3.Vec) []float64 {
+	rel, _ := stationRelative(r.Station, t, SBI, VBI)
+	return []float64{md3.Norm(rel)}
+}
+
+// Noise implements [Measurement].
+func (r Range) Noise() []float64 { return []float64{r.Sigma} }
+
+// RangeRate is the rate of change of the slant range [m/s] from a ground station to the body.
+type RangeRate struct {
+	Station gnco.GeocentricCoords
+	Sigma   float64 // Standard deviation of range rate [m/s].
+}
+
+// Predict returns the range rate. It implements [Measurement].
+func (rr RangeRate) Predict(t float64, SBI, VBI md3.Vec) []float64 {
+	rel, vrel := stationRelative(rr.Station, t, SBI, VBI)
+	return []float64{md3.Dot(rel, vrel) / md3.Norm(rel)}
+}
+
+// Noise implements [Measurement].
+func (rr RangeRate) Noise() []float64 { return []float64{rr.Sigma} }
+
+// AzEl is the azimuth [rad] and elevation [rad] of the body as seen from a ground station.
+// Azimuth is measured clockwise from North in range [0, 2pi) as in [gnco.GeocentricCoords.LookAngles].
+type AzEl struct {
+	Station gnco.GeocentricCoords
+	SigmaAz float64 // Standard deviation of azimuth [rad].
+	SigmaEl float64 // Standard deviation of elevation [rad].
+}
+
+// Predict returns the azimuth and elevation. It implements [Measurement].
+func (ae AzEl) Predict(t float64, SBI, VBI md3.Vec) []float64 {
+	azimuth, elevation, _ := ae.Station.LookAnglesInertial(t, SBI)
+	return []float64{azimuth, elevation}
+}
+
+// Noise implements [Measurement].
+func (ae AzEl) Noise() []float64 { return []float64{ae.SigmaAz, ae.SigmaEl} }
+
+// Residual returns z - zhat with the azimuth difference wrapped to [-pi, pi).
+func (ae AzEl) Residual(z, zhat []float64) []float64 {
+	daz := math.Mod(z[0]-zhat[0]+3*math.Pi, 2*math.Pi) - math.Pi
+	return []float64{daz, z[1] - zhat[1]}
+}
+
+// GPSPosition is a position fix of the body in the world's earth fixed frame (ECEF) [m].
+type GPSPosition struct {
+	World *gnco.World
+	Sigma float64 // Standard deviation of each position component [m].
+}
+
+// Predict returns the earth fixed position. It implements [Measurement].
+func (g GPSPosition) Predict(t float64, SBI, VBI md3.Vec) []float64 {
+	SBE := md3.MulMatVec(g.World.TEI(t), SBI)
+	return []float64{SBE.X, SBE.Y, SBE.Z}
+}
+
+// Noise implements [Measurement].
+func (g GPSPosition) Noise() []float64 { return []float64{g.Sigma, g.Sigma, g.Sigma} }
+
+// stationRelative returns the position and velocity of the body relative to a
+// station fixed to the rotating world in inertial frame.
+func stationRelative(station gnco.GeocentricCoords, t float64, SBI, VBI md3.Vec) (rel, vrel md3.Vec) {
+	SSI, _ := station.InertialCoords(t)
+	omega := station.World().Rotation
+	VSI := md3.Vec{X: -omega * SSI.Y, Y: omega * SSI.X}
+	return md3.Sub(SBI, SSI), md3.Sub(VBI, VSI)
+}
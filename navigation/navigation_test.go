@@ -0,0 +1,174 @@
+package navigation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+const deg = math.Pi / 180
+
+// trackingScenario returns a ground station and the truth integrator of a satellite in a 1000km
+// equatorial orbit that passes over the station during the first 600 seconds.
+func trackingScenario(t *testing.T) (station gnco.GeocentricCoords, truth *gnco.PhysicsPointIntegrator) {
+	t.Helper()
+	earth := gnco.NewEarth()
+	station = earth.GeocentricFromDegrees(0, 0, 0)
+	SSI, _ := station.InertialCoords(0)
+	r := earth.SemiMajorAxis + 1000e3
+	v := math.Sqrt(earth.G() / r)
+	// Satellite starts 17 degrees behind the station.
+	sin, cos := math.Sincos(math.Atan2(SSI.Y, SSI.X) - 17*deg)
+	SBI := md3.Vec{X: r * cos, Y: r * sin}
+	VBI := md3.Vec{X: -v * sin, Y: v * cos}
+	coords := earth.GeocentricFromDegrees(0, 0, 0).Geodesic()
+	return station, gnco.NewPhysicsPointIntegrator(&coords, 0, SBI, VBI)
+}
+
+// filterPhys returns an integrator with the same dynamics as the truth at an erroneous initial state.
+func filterPhys(truth *gnco.PhysicsPointIntegrator, errPos, errVel md3.Vec) *gnco.PhysicsPointIntegrator {
+	t, SBI, VBI := truth.State()
+	coords := gnco.NewEarth().GeocentricFromDegrees(0, 0, 0).Geodesic()
+	return gnco.NewPhysicsPointIntegrator(&coords, t, md3.Add(SBI, errPos), md3.Add(VBI, errVel))
+}
+
+func initialCovariance(sigmaPos, sigmaVel float64) (P0 [StateDim][StateDim]float64) {
+	for i := 0; i < 3; i++ {
+		P0[i][i] = sigmaPos * sigmaPos
+		P0[i+3][i+3] = sigmaVel * sigmaVel
+	}
+	return P0
+}
+
+type kalmanFilter interface {
+	Update(t float64, m Measurement, z []float64) error
+	State() (t float64, SBI, VBI md3.Vec)
+	Covariance() [StateDim][StateDim]float64
+}
+
+func TestGroundStationTracking(t *testing.T) {
+	station, _ := trackingScenario(t)
+	models := []Measurement{
+		Range{Station: station, Sigma: 10},
+		RangeRate{Station: station, Sigma: 0.01},
+		AzEl{Station: station, SigmaAz: 0.01 * deg, SigmaEl: 0.01 * deg},
+	}
+	newFilters := map[string]func(phys *gnco.PhysicsPointIntegrator, P0 [StateDim][StateDim]float64) kalmanFilter{
+		"EKF": func(phys *gnco.PhysicsPointIntegrator, P0 [StateDim][StateDim]float64) kalmanFilter {
+			return NewEKF(phys, P0)
+		},
+		"UKF": func(phys *gnco.PhysicsPointIntegrator, P0 [StateDim][StateDim]float64) kalmanFilter {
+			return NewUKF(phys, P0)
+		},
+	}
+	for name, newFilter := range newFilters {
+		_, truth := trackingScenario(t)
+		rng := rand.New(rand.NewSource(1))
+		kf := newFilter(filterPhys(truth, md3.Vec{X: 1000, Y: -700, Z: 500}, md3.Vec{X: -1, Y: 0.5, Z: 1}), initialCovariance(1000, 1))
+		nobs := 0
+		for tm := 10.; tm <= 600; tm += 10 {
+			SBI, VBI := truth.Propagate(tm, 10)
+			if el := (AzEl{Station: station}).Predict(tm, SBI, VBI)[1]; el < 5*deg {
+				continue // Below elevation mask.
+			}
+			for _, m := range models {
+				z := m.Predict(tm, SBI, VBI)
+				for i, sigma := range m.Noise() {
+					z[i] += sigma * rng.NormFloat64()
+				}
+				if err := kf.Update(tm, m, z); err != nil {
+					t.Fatal(name, err)
+				}
+			}
+			nobs++
+		}
+		if nobs < 30 {
+			t.Fatalf("%s: too few observations during pass: %d", name, nobs)
+		}
+		tm, SBI, VBI := kf.State()
+		_, SBItrue, VBItrue := truth.State()
+		errPos := md3.Norm(md3.Sub(SBI, SBItrue))
+		errVel := md3.Norm(md3.Sub(VBI, VBItrue))
+		P := kf.Covariance()
+		sigmaPos := math.Sqrt(P[0][0] + P[1][1] + P[2][2])
+		t.Logf("%s t=%g position error %.2fm (1σ %.2fm) velocity error %.4fm/s", name, tm, errPos, sigmaPos, errVel)
+		if errPos > 50 || errVel > 0.1 {
+			t.Errorf("%s: estimate error too large: %gm, %gm/s", name, errPos, errVel)
+		}
+		if errPos > 3*sigmaPos {
+			t.Errorf("%s: position error %gm inconsistent with covariance 1σ %gm", name, errPos, sigmaPos)
+		}
+	}
+}
+
+func TestGPSNavigation(t *testing.T) {
+	_, truth := trackingScenario(t)
+	w := gnco.NewEarth()
+	gps := GPSPosition{World: w, Sigma: 5}
+	kf := NewEKF(filterPhys(truth, md3.Vec{X: -800, Z: 800}, md3.Vec{Y: 2}), initialCovariance(1000, 2))
+	kf.ProcessNoise = 1e-8
+	rng := rand.New(rand.NewSource(2))
+	for tm := 1.; tm <= 300; tm++ {
+		SBI, VBI := truth.Propagate(tm, 10)
+		z := gps.Predict(tm, SBI, VBI)
+		for i := range z {
+			z[i] += gps.Sigma * rng.NormFloat64()
+		}
+		if err := kf.Update(tm, gps, z); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, SBI, VBI := kf.State()
+	_, SBItrue, VBItrue := truth.State()
+	errPos := md3.Norm(md3.Sub(SBI, SBItrue))
+	errVel := md3.Norm(md3.Sub(VBI, VBItrue))
+	if errPos > 5 || errVel > 0.1 {
+		t.Errorf("GPS estimate error too large: %gm, %gm/s", errPos, errVel)
+	}
+}
+
+func TestCovariancePropagation(t *testing.T) {
+	// Without process noise the EKF and UKF must agree on the propagated covariance for
+	// small uncertainties and the along-track uncertainty must grow from the velocity uncertainty.
+	_, truth := trackingScenario(t)
+	P0 := initialCovariance(10, 0.01)
+	ekf := NewEKF(filterPhys(truth, md3.Vec{}, md3.Vec{}), P0)
+	ukf := NewUKF(filterPhys(truth, md3.Vec{}, md3.Vec{}), P0)
+	const tf = 3000.
+	ekf.Predict(tf)
+	if err := ukf.Predict(tf); err != nil {
+		t.Fatal(err)
+	}
+	Pe, Pu := ekf.Covariance(), ukf.Covariance()
+	for i := range Pe {
+		for j := range Pe {
+			scale := math.Sqrt(Pe[i][i] * Pe[j][j])
+			if math.Abs(Pe[i][j]-Pu[i][j]) > 1e-3*scale {
+				t.Errorf("covariance (%d,%d) EKF %g UKF %g", i, j, Pe[i][j], Pu[i][j])
+			}
+			if Pe[i][j] != Pe[j][i] {
+				t.Errorf("covariance not symmetric at (%d,%d)", i, j)
+			}
+		}
+	}
+	_, SBIe, _ := ekf.State()
+	SBItrue, _ := truth.Propagate(tf, 10)
+	if d := md3.Norm(md3.Sub(SBIe, SBItrue)); d > 1e-3 {
+		t.Errorf("EKF mean propagation differs from truth by %gm", d)
+	}
+	sigmaPos := math.Sqrt(Pe[0][0] + Pe[1][1] + Pe[2][2])
+	if sigmaPos < 10*math.Sqrt(3) {
+		t.Errorf("position uncertainty did not grow: %gm", sigmaPos)
+	}
+}
+
+func TestAzElResidualWrap(t *testing.T) {
+	ae := AzEl{}
+	dz := ae.Residual([]float64{0.1 * deg, 10 * deg}, []float64{359.9 * deg, 9 * deg})
+	if math.Abs(dz[0]-0.2*deg) > 1e-12 || math.Abs(dz[1]-1*deg) > 1e-12 {
+		t.Errorf("bad residual %v", dz)
+	}
+}
@@ -0,0 +1,194 @@
+package navigation
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/control"
+)
+
+// TriadErrors describes the errors of three orthogonal inertial sensors. Units are those of
+// the sensed quantity u: [m.s^-2] for accelerometers and [rad/s] for gyroscopes.
+// A measurement m of the true quantity x is modeled as
+//
+//	m = quantize((I + diag(ScaleFactor) + Misalignment)*x + Bias + b(t) + n)
+//
+// where b(t) is a bias random walk and n is white noise.
+type TriadErrors struct {
+	// Bias is the constant bias [u].
+	Bias md3.Vec
+	// ScaleFactor is the scale factor error of each axis [Adim].
+	ScaleFactor md3.Vec
+	// Misalignment holds the small angle cross-axis coupling of the sensor axes [rad].
+	// Its diagonal should be zero.
+	Misalignment md3.Mat3
+	// NoiseDensity is the white noise density [u/√Hz], known as velocity random walk for
+	// accelerometers and angle random walk for gyroscopes.
+	NoiseDensity float64
+	// BiasRandomWalk is the density of the bias random walk [u/s/√Hz].
+	BiasRandomWalk float64
+	// Quantization is the least significant bit of the output [u]. Zero disables quantization.
+	Quantization float64
+}
+
+// triad is a sensor triad with its error state.
+type triad struct {
+	errs TriadErrors
+	bias md3.Vec // Random walk part of bias.
+	// Quantization residual carried to the next sample so quantization error does not accumulate.
+	residual md3.Vec
+}
+
+// measure returns the measurement of x after dt [s] since the previous sample.
+func (tr *triad) measure(rng *rand.Rand, x md3.Vec, dt float64) md3.Vec {
+	e := &tr.errs
+	if dt > 0 {
+		tr.bias = md3.Add(tr.bias, md3.Scale(e.BiasRandomWalk*math.Sqrt(dt), gaussVec(rng)))
+	}
+	scale := md3.AddMat3(md3.AddMat3(md3.IdentityMat3(), diagMat3(e.ScaleFactor)), e.Misalignment)
+	m := md3.Add(md3.MulMatVec(scale, x), md3.Add(e.Bias, tr.bias))
+	if dt > 0 {
+		m = md3.Add(m, md3.Scale(e.NoiseDensity/math.Sqrt(dt), gaussVec(rng)))
+	}
+	if q := e.Quantization; q > 0 {
+		m = md3.Add(m, tr.residual)
+		quant := md3.Vec{X: q * math.Round(m.X/q), Y: q * math.Round(m.Y/q), Z: q * math.Round(m.Z/q)}
+		tr.residual = md3.Sub(m, quant)
+		m = quant
+	}
+	return m
+}
+
+// IMU simulates a strapdown inertial measurement unit composed of an accelerometer and
+// a gyroscope triad aligned with the body frame. Random errors are drawn from a seeded
+// generator so simulations are reproducible.
+type IMU struct {
+	Sampler control.Sampler
+	accel   triad
+	gyro    triad
+	rng     *rand.Rand
+	f, w    md3.Vec
+}
+
+// NewIMU returns an IMU sampled every period seconds with accelerometer and gyroscope errors
+// whose random components are drawn from a generator seeded with seed.
+func NewIMU(accel, gyro TriadErrors, period float64, seed int64) *IMU {
+	return &IMU{
+		Sampler: control.Sampler{Period: period},
+		accel:   triad{errs: accel},
+		gyro:    triad{errs: gyro},
+		rng:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Measure samples the true specific force [m.s^-2] and angular rate of the body with respect to
+// inertial space [rad/s], both in body frame, at time t. It returns the measured specific force
+// and angular rate and whether a new sample was taken. Between samples the last outputs are held.
+func (imu *IMU) Measure(t float64, specificForceB, angularRateB md3.Vec) (fB, wB md3.Vec, ok bool) {
+	if !imu.Sampler.Due(t) {
+		return imu.f, imu.w, false
+	}
+	dt := imu.Sampler.Period
+	imu.f = imu.accel.measure(imu.rng, specificForceB, dt)
+	imu.w = imu.gyro.measure(imu.rng, angularRateB, dt)
+	return imu.f, imu.w, true
+}
+
+// GPSFix is a GPS position and velocity solution in the world's earth fixed frame (ECEF).
+type GPSFix struct {
+	T   float64 // Epoch time of the fix [s].
+	SBE md3.Vec // Position [m].
+	VBE md3.Vec // Velocity relative to earth fixed frame [m/s].
+}
+
+// GPS simulates a GPS receiver providing earth fixed position and velocity fixes with white
+// noise at a fixed update rate. Noise is drawn from a seeded generator so simulations are reproducible.
+type GPS struct {
+	World   *gnco.World
+	Sampler control.Sampler
+	// Standard deviation of each component of position [m] and velocity [m/s].
+	SigmaPosition, SigmaVelocity float64
+	rng                          *rand.Rand
+	fix                          GPSFix
+}
+
+// NewGPS returns a GPS receiver on world w producing a fix every period seconds.
+func NewGPS(w *gnco.World, sigmaPos, sigmaVel, period float64, seed int64) *GPS {
+	return &GPS{
+		World:         w,
+		Sampler:       control.Sampler{Period: period},
+		SigmaPosition: sigmaPos,
+		SigmaVelocity: sigmaVel,
+		rng:           rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Measure returns the latest fix of a body at inertial position SBI and velocity VBI at time t and
+// whether it is a new fix. Between updates the last fix is held.
+func (g *GPS) Measure(t float64, SBI, VBI md3.Vec) (fix GPSFix, ok bool) {
+	if !g.Sampler.Due(t) {
+		return g.fix, false
+	}
+	SBE, VBE := EarthFixedState(g.World, t, SBI, VBI)
+	g.fix = GPSFix{
+		T:   t,
+		SBE: md3.Add(SBE, md3.Scale(g.SigmaPosition, gaussVec(g.rng))),
+		VBE: md3.Add(VBE, md3.Scale(g.SigmaVelocity, gaussVec(g.rng))),
+	}
+	return g.fix, true
+}
+
+// EarthFixedState returns the earth fixed position [m] and velocity relative to the earth fixed
+// frame [m/s] of a body at inertial position SBI and velocity VBI at epoch time t.
+func EarthFixedState(w *gnco.World, t float64, SBI, VBI md3.Vec) (SBE, VBE md3.Vec) {
+	TEI := w.TEI(t)
+	vrel := md3.Vec{X: VBI.X + w.Rotation*SBI.Y, Y: VBI.Y - w.Rotation*SBI.X, Z: VBI.Z}
+	return md3.MulMatVec(TEI, SBI), md3.MulMatVec(TEI, vrel)
+}
+
+// SpecificForce returns the specific force acting on the body integrated by phys in body frame
+// [m.s^-2] which is the non-gravitational acceleration sensed by an accelerometer. It is the sum of
+// the contributions of the force models of phys not named in gravitational plus the external
+// acceleration in geographic frame passed to the integrator's Step. If gravitational is empty only
+// the "gravity" force model is excluded.
+func SpecificForce(phys *gnco.PhysicsPointIntegrator, orient gnco.Orientation, externalAccelG md3.Vec, gravitational ...string) md3.Vec {
+	if len(gravitational) == 0 {
+		gravitational = []string{"gravity"}
+	}
+	fI := gnco.FrameGeographic.ToInertial(orient, externalAccelG)
+CONTRIBUTIONS:
+	for _, c := range phys.Forces(nil) {
+		for _, name := range gravitational {
+			if c.Name == name {
+				continue CONTRIBUTIONS
+			}
+		}
+		fI = md3.Add(fI, c.AccelInertial)
+	}
+	return gnco.FrameInertial.ToBody(orient, fI)
+}
+
+// AngularRate returns the angular rate of the body with respect to inertial space in body frame
+// [rad/s] at the midpoint of two orientations o0 and o1 dt [s] apart.
+func AngularRate(o0, o1 gnco.Orientation, dt float64) md3.Vec {
+	// Rotation from body frame at t0 to body frame at t1 is TBI1*TBI0ᵀ = I - [θ×] for small rotations.
+	dT := md3.MulMat3(bodyRotation(o1), bodyRotation(o0).Transpose())
+	a := dT.Array()
+	theta := md3.Vec{X: a[5] - a[7], Y: a[6] - a[2], Z: a[1] - a[3]}
+	return md3.Scale(0.5/dt, theta)
+}
+
+// bodyRotation returns the rotation tensor of body wrt inertial coordinates.
+func bodyRotation(o gnco.Orientation) md3.Mat3 {
+	return md3.MulMat3(o.TBV, md3.MulMat3(o.TVG, o.TGI))
+}
+
+func gaussVec(rng *rand.Rand) md3.Vec {
+	return md3.Vec{X: rng.NormFloat64(), Y: rng.NormFloat64(), Z: rng.NormFloat64()}
+}
+
+func diagMat3(d md3.Vec) md3.Mat3 {
+	return md3.NewMat3([]float64{d.X, 0, 0, 0, d.Y, 0, 0, 0, d.Z})
+}
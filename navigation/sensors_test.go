@@ -0,0 +1,132 @@
+package navigation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+	"github.com/soypat/gnco"
+)
+
+func TestIMUDeterministicErrors(t *testing.T) {
+	accel := TriadErrors{
+		Bias:         md3.Vec{X: 0.01, Y: -0.02, Z: 0.03},
+		ScaleFactor:  md3.Vec{X: 1e-3, Y: -2e-3},
+		Misalignment: md3.NewMat3([]float64{0, 1e-3, 0, 0, 0, 0, -2e-3, 0, 0}),
+	}
+	gyro := TriadErrors{Bias: md3.Vec{Z: 1e-4}}
+	imu := NewIMU(accel, gyro, 0.01, 1)
+	f, w := md3.Vec{X: 10, Y: 1, Z: -9.8}, md3.Vec{X: 0.1}
+	fB, wB, ok := imu.Measure(0, f, w)
+	if !ok {
+		t.Fatal("first call must sample")
+	}
+	want := md3.Vec{X: 10*1.001 + 1e-3 + 0.01, Y: 1*0.998 - 0.02, Z: -9.8 - 2e-3*10 + 0.03}
+	if !md3.EqualElem(fB, want, 1e-12) {
+		t.Errorf("specific force want %v, got %v", want, fB)
+	}
+	if !md3.EqualElem(wB, md3.Vec{X: 0.1, Z: 1e-4}, 1e-15) {
+		t.Errorf("angular rate got %v", wB)
+	}
+	if _, _, ok = imu.Measure(0.005, f, w); ok {
+		t.Error("sampled before period elapsed")
+	}
+}
+
+func TestIMURandomErrors(t *testing.T) {
+	const dt, n = 0.01, 20000
+	accel := TriadErrors{NoiseDensity: 1e-3, Quantization: 1e-3}
+	gyro := TriadErrors{BiasRandomWalk: 1e-4}
+	imu := NewIMU(accel, gyro, dt, 42)
+	f := md3.Vec{X: 1.23456}
+	var sum, sum2 float64
+	for i := 0; i < n; i++ {
+		fB, _, _ := imu.Measure(float64(i)*dt, f, md3.Vec{})
+		if q := fB.Y / 1e-3; math.Abs(q-math.Round(q)) > 1e-9 {
+			t.Fatalf("output not quantized: %g", fB.Y)
+		}
+		sum += fB.X
+		sum2 += fB.X * fB.X
+	}
+	mean := sum / n
+	std := math.Sqrt(sum2/n - mean*mean)
+	wantStd := math.Hypot(1e-3/math.Sqrt(dt), 1e-3/math.Sqrt(12))
+	if math.Abs(mean-f.X) > 3*wantStd/math.Sqrt(n) {
+		t.Errorf("accelerometer mean biased: %g", mean-f.X)
+	}
+	if math.Abs(std-wantStd) > 0.05*wantStd {
+		t.Errorf("accelerometer noise want %g, got %g", wantStd, std)
+	}
+	// Gyro bias random walk standard deviation grows as sqrt(t).
+	_, wB, _ := imu.Measure(n*dt, f, md3.Vec{})
+	if math.Abs(wB.X) > 5e-4*math.Sqrt(n*dt) || wB == (md3.Vec{}) {
+		t.Errorf("unexpected gyro bias random walk %v", wB)
+	}
+
+	// Same seed reproduces the same measurements.
+	a, b := NewIMU(accel, gyro, dt, 7), NewIMU(accel, gyro, dt, 7)
+	for i := 0; i < 10; i++ {
+		fa, wa, _ := a.Measure(float64(i)*dt, f, md3.Vec{})
+		fb, wb, _ := b.Measure(float64(i)*dt, f, md3.Vec{})
+		if fa != fb || wa != wb {
+			t.Fatal("measurements not reproducible")
+		}
+	}
+}
+
+func TestGPSReceiver(t *testing.T) {
+	earth := gnco.NewEarth()
+	station := earth.GeocentricFromDegrees(-58, -34, 0)
+	gps := NewGPS(earth, 3, 0.05, 1, 1)
+	var nfix int
+	var sumPos float64
+	for tm := 0.; tm < 1000; tm += 0.1 {
+		// A body at rest on the ground.
+		SBI, _ := station.InertialCoords(tm)
+		VBI := md3.Vec{X: -earth.Rotation * SBI.Y, Y: earth.Rotation * SBI.X}
+		fix, ok := gps.Measure(tm, SBI, VBI)
+		if !ok {
+			continue
+		}
+		nfix++
+		sumPos += md3.Norm2(md3.Sub(fix.SBE, station.EarthFixedCoords()))
+		if md3.Norm(fix.VBE) > 0.5 {
+			t.Fatalf("velocity of body at rest too large: %v", fix.VBE)
+		}
+	}
+	if nfix != 1000 {
+		t.Errorf("want 1000 fixes, got %d", nfix)
+	}
+	if rms := math.Sqrt(sumPos / float64(nfix)); math.Abs(rms-3*math.Sqrt(3)) > 0.3 {
+		t.Errorf("position noise rms %g", rms)
+	}
+}
+
+func TestTruthFromIntegrator(t *testing.T) {
+	earth := gnco.NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	phys := gnco.NewPhysicsPointIntegrator(&coords, 0, md3.Vec{X: 7000e3}, md3.Vec{Y: 7000})
+	thrust := md3.Vec{Y: 2}
+	phys.AddForceModel("thrust", gnco.ForceFunc(func(s *gnco.ForceState) md3.Vec { return thrust }))
+	// Body spinning about body X axis at 0.1rad/s aligned with the velocity frame.
+	const rate, dt = 0.1, 0.01
+	orient := func(tm float64) gnco.Orientation {
+		_, SBI, VBI := phys.State()
+		coords := earth.GeocentricFromEarthFixedCoords(md3.MulMatVec(earth.TEI(tm), SBI))
+		TGI := coords.TGI(tm)
+		TVG := gnco.TVGFromVelocity(md3.MulMatVec(TGI, VBI))
+		s, c := math.Sincos(rate * tm)
+		TBV := md3.NewMat3([]float64{1, 0, 0, 0, c, s, 0, -s, c})
+		return gnco.Orientation{TBV: TBV, TVG: TVG, TGI: TGI}
+	}
+	o := orient(1)
+	fB := SpecificForce(phys, o, md3.Vec{})
+	if got := gnco.FrameBody.ToInertial(o, fB); !md3.EqualElem(got, thrust, 1e-12) {
+		t.Errorf("specific force want %v, got %v", thrust, got)
+	}
+	w := AngularRate(orient(1-dt/2), orient(1+dt/2), dt)
+	// Velocity frame rotates slowly with orbital and Earth rates compared to the spin.
+	if math.Abs(w.X-rate) > 2e-3 || math.Hypot(w.Y, w.Z) > 2e-3 {
+		t.Errorf("angular rate got %v", w)
+	}
+}
@@ -0,0 +1,160 @@
+package navigation
+
+import (
+	"github.com/soypat/gnco"
+	"github.com/soypat/gnco/internal/linalg"
+)
+
+// UKF is an unscented Kalman filter estimating the inertial position and velocity of a body. The state
+// and covariance are propagated by integrating a set of sigma points with a [gnco.PhysicsPointIntegrator]
+// which captures nonlinearities of the dynamics and measurements to second order.
+// See Wan, van der Merwe - The unscented Kalman filter for nonlinear estimation (2000).
+type UKF struct {
+	// MaxStep is the largest integrator step used to propagate the state [s].
+	MaxStep float64
+	// ProcessNoise is the power spectral density of the unmodeled acceleration [m^2.s^-3].
+	ProcessNoise float64
+	// Sigma point spread parameters. Alpha sets the spread around the mean, Beta incorporates
+	// prior knowledge of the distribution (2 is optimal for Gaussian) and Kappa is a secondary scaling.
+	Alpha, Beta, Kappa float64
+	filter
+}
+
+// NewUKF returns an unscented Kalman filter using phys as process model. The initial estimate is
+// the current state of phys with covariance P0. The filter takes ownership of phys' state.
+// The spread parameters are initialized to Alpha=1, Beta=2 and Kappa=0.
+func NewUKF(phys *gnco.PhysicsPointIntegrator, P0 [StateDim][StateDim]float64) *UKF {
+	return &UKF{MaxStep: 10, Alpha: 1, Beta: 2, filter: newFilter(phys, P0)}
+}
+
+// Predict propagates the estimate and its covariance to epoch time t. An error is returned
+// if the covariance is not positive definite.
+func (u *UKF) Predict(t float64) error {
+	if t == u.t {
+		return nil
+	}
+	X, err := u.sigmaPoints()
+	if err != nil {
+		return err
+	}
+	for i := range X {
+		X[i] = u.propagate(X[i], u.t, t, u.MaxStep)
+	}
+	wm, wc := u.weights()
+	var x [StateDim]float64
+	for i := range X {
+		for j := range x {
+			x[j] += wm[i] * X[i][j]
+		}
+	}
+	P := processNoise(u.ProcessNoise, t-u.t)
+	for i := range X {
+		addOuter(P, wc[i], diff(X[i][:], x[:]), diff(X[i][:], x[:]))
+	}
+	P.Symmetrize()
+	u.t, u.x, u.P = t, x, P
+	return nil
+}
+
+// Update propagates the estimate to epoch time t and corrects it with the observation z of measurement m.
+// An error is returned if z does not match the dimension of m or a covariance is not positive definite.
+func (u *UKF) Update(t float64, m Measurement, z []float64) error {
+	R, err := noiseCovariance(m, z)
+	if err != nil {
+		return err
+	}
+	if err = u.Predict(t); err != nil {
+		return err
+	}
+	X, err := u.sigmaPoints()
+	if err != nil {
+		return err
+	}
+	wm, wc := u.weights()
+	Z := make([][]float64, len(X))
+	for i := range X {
+		Z[i] = predict(m, t, X[i])
+	}
+	// Mean taken about the central sigma point so wrapped components are averaged correctly.
+	zhat := append([]float64(nil), Z[0]...)
+	for i := range Z {
+		dz := residual(m, Z[i], Z[0])
+		for j := range zhat {
+			zhat[j] += wm[i] * dz[j]
+		}
+	}
+	Pzz := R
+	Pxz := linalg.New(StateDim, len(z))
+	for i := range X {
+		dz := residual(m, Z[i], zhat)
+		addOuter(Pzz, wc[i], dz, dz)
+		addOuter(Pxz, wc[i], diff(X[i][:], u.x[:]), dz)
+	}
+	PzzInv, err := linalg.Inverse(Pzz)
+	if err != nil {
+		return err
+	}
+	K := linalg.Mul(Pxz, PzzInv)
+	dx := linalg.MulVec(K, residual(m, z, zhat))
+	for i := range u.x {
+		u.x[i] += dx[i]
+	}
+	P := linalg.Add(u.P, -1, linalg.Mul(linalg.Mul(K, Pzz), K.T()))
+	P.Symmetrize()
+	u.P = P
+	return nil
+}
+
+func (u *UKF) lambda() float64 {
+	return u.Alpha*u.Alpha*(StateDim+u.Kappa) - StateDim
+}
+
+// sigmaPoints returns the 2n+1 sigma points of the current estimate.
+func (u *UKF) sigmaPoints() ([][StateDim]float64, error) {
+	L, err := linalg.Cholesky(linalg.Add(linalg.New(StateDim, StateDim), StateDim+u.lambda(), u.P))
+	if err != nil {
+		return nil, err
+	}
+	X := make([][StateDim]float64, 2*StateDim+1)
+	X[0] = u.x
+	for j := 0; j < StateDim; j++ {
+		X[1+j], X[1+StateDim+j] = u.x, u.x
+		for i := 0; i < StateDim; i++ {
+			X[1+j][i] += L.At(i, j)
+			X[1+StateDim+j][i] -= L.At(i, j)
+		}
+	}
+	return X, nil
+}
+
+// weights returns the weights of the sigma points for the mean and covariance.
+func (u *UKF) weights() (wm, wc []float64) {
+	lambda := u.lambda()
+	n := 2*StateDim + 1
+	wm, wc = make([]float64, n), make([]float64, n)
+	wm[0] = lambda / (StateDim + lambda)
+	wc[0] = wm[0] + 1 - u.Alpha*u.Alpha + u.Beta
+	for i := 1; i < n; i++ {
+		wm[i] = 1 / (2 * (StateDim + lambda))
+		wc[i] = wm[i]
+	}
+	return wm, wc
+}
+
+// addOuter adds w*a*bᵀ to m.
+func addOuter(m *linalg.Dense, w float64, a, b []float64) {
+	for i := range a {
+		row := m.Row(i)
+		for j := range b {
+			row[j] += w * a[i] * b[j]
+		}
+	}
+}
+
+func diff(a, b []float64) []float64 {
+	d := make([]float64, len(a))
+	for i := range a {
+		d[i] = a[i] - b[i]
+	}
+	return d
+}
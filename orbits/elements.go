@@ -0,0 +1,102 @@
+package orbits
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Elements are the classical orbital elements of a body in inertial frame.
+// Angles are in radians. For circular orbits the argument of periapsis is zero and the true anomaly
+// is measured from the ascending node. For equatorial orbits the ascending node is taken along the X axis.
+type Elements struct {
+	SemiMajorAxis float64 // Semimajor axis, negative for hyperbolic orbits [m].
+	Eccentricity  float64 // [Adim]
+	Inclination   float64 // Angle between orbital angular momentum and Z axis in [0, π].
+	RAAN          float64 // Right ascension of the ascending node in [0, 2π).
+	ArgPeriapsis  float64 // Argument of periapsis measured from the ascending node in [0, 2π).
+	TrueAnomaly   float64 // True anomaly in [0, 2π).
+}
+
+// NewElementsFromState returns the classical orbital elements of a body at inertial position r [m] with
+// velocity v [m/s] around a world of gravitational parameter gravParam [m^3.s^-2].
+// Curtis, Howard's Orbital Mechanics, Algorithm 4.2.
+func NewElementsFromState(r, v md3.Vec, gravParam float64) (Elements, error) {
+	const tol = 1e-10
+	rn := md3.Norm(r)
+	h := md3.Cross(r, v)
+	hn := md3.Norm(h)
+	if rn == 0 || hn == 0 {
+		return Elements{}, errors.New("rectilinear orbit has no orbital elements")
+	}
+	energy := md3.Norm2(v)/2 - gravParam/rn
+	if math.Abs(energy) < tol*gravParam/rn {
+		return Elements{}, errors.New("parabolic orbit has no semimajor axis")
+	}
+	// Eccentricity vector points to periapsis.
+	ev := md3.Scale(1/gravParam, md3.Sub(md3.Scale(md3.Norm2(v)-gravParam/rn, r), md3.Scale(md3.Dot(r, v), v)))
+	el := Elements{
+		SemiMajorAxis: -gravParam / (2 * energy),
+		Eccentricity:  md3.Norm(ev),
+		Inclination:   math.Acos(math.Max(-1, math.Min(h.Z/hn, 1))),
+	}
+	node := md3.Vec{X: -h.Y, Y: h.X} // Z×h.
+	if md3.Norm(node) < tol*hn {
+		node = md3.Vec{X: 1} // Equatorial orbit.
+	}
+	node = md3.Unit(node)
+	el.RAAN = wrapAngle(math.Atan2(node.Y, node.X))
+	// Angles within the orbit plane measured from the node about h.
+	inPlane := md3.Cross(md3.Scale(1/hn, h), node)
+	angle := func(u md3.Vec) float64 {
+		return wrapAngle(math.Atan2(md3.Dot(u, inPlane), md3.Dot(u, node)))
+	}
+	argLatitude := angle(r)
+	if el.Eccentricity > tol {
+		el.ArgPeriapsis = angle(ev)
+	}
+	el.TrueAnomaly = wrapAngle(argLatitude - el.ArgPeriapsis)
+	return el, nil
+}
+
+// State returns the inertial position [m] and velocity [m/s] of a body with elements el around a world of
+// gravitational parameter gravParam [m^3.s^-2]. Curtis, Howard's Orbital Mechanics, Algorithm 4.5.
+func (el Elements) State(gravParam float64) (r, v md3.Vec) {
+	e := el.Eccentricity
+	p := el.SemiMajorAxis * (1 - e*e) // Semilatus rectum.
+	sinv, cosv := math.Sincos(el.TrueAnomaly)
+	rPerifocal := md3.Scale(p/(1+e*cosv), md3.Vec{X: cosv, Y: sinv})
+	vPerifocal := md3.Scale(math.Sqrt(gravParam/p), md3.Vec{X: -sinv, Y: e + cosv})
+	T := el.tPerifocal()
+	return md3.MulMatVec(T, rPerifocal), md3.MulMatVec(T, vPerifocal)
+}
+
+// Orbit returns the shape of the orbit. It returns an error for unbound orbits.
+func (el Elements) Orbit() (Elliptical, error) {
+	if el.Eccentricity >= 1 || el.SemiMajorAxis <= 0 {
+		return Elliptical{}, errors.New("orbit is not bound")
+	}
+	return NewElliptical(el.SemiMajorAxis*(1+el.Eccentricity), el.SemiMajorAxis*(1-el.Eccentricity))
+}
+
+// tPerifocal returns the rotation tensor of inertial wrt perifocal coordinates.
+func (el Elements) tPerifocal() md3.Mat3 {
+	sO, cO := math.Sincos(el.RAAN)
+	si, ci := math.Sincos(el.Inclination)
+	sw, cw := math.Sincos(el.ArgPeriapsis)
+	return md3.NewMat3([]float64{
+		cO*cw - sO*sw*ci, -cO*sw - sO*cw*ci, sO * si,
+		sO*cw + cO*sw*ci, -sO*sw + cO*cw*ci, -cO * si,
+		sw * si, cw * si, ci,
+	})
+}
+
+// wrapAngle returns angle wrapped to [0, 2π).
+func wrapAngle(angle float64) float64 {
+	angle = math.Mod(angle, 2*math.Pi)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
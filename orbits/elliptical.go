@@ -5,6 +5,7 @@ import (
 	"math"
 
 	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
 )
 
 // Orbit defines a typical earthbound circular or elliptic orbit at
@@ -144,7 +145,7 @@ func (o Elliptical) EccentricAnomaly(trueAnomaly float64) float64 {
 func (o Elliptical) AngularMomentum(gravParam float64) float64 {
 	// We evaluate the orbit equation at perigee where trueAnomaly==0
 	// and solve for h.l
-	return math.Sqrt(gravParam * o.rp * (1 + o.Eccentricity()))
+	return math.Sqrt(gravParam * o.Periapsis() * (1 + o.Eccentricity()))
 }
 
 func (o Elliptical) SpecificEnergy(gravParam float64) float64 {
@@ -161,6 +162,11 @@ func (o Elliptical) Period(gravParam float64) float64 {
 	return 2 * math.Pi * math.Sqrt(a*a*a/gravParam) // Eqn (2.83)
 }
 
+// MeanMotion returns the mean angular rate of the body along the orbit. [rad/s]
+func (o Elliptical) MeanMotion(gravParam float64) float64 {
+	return 2 * math.Pi / o.Period(gravParam)
+}
+
 // ElapsedSincePeriapsis returns the seconds elapsed since periapsis.
 // This function will return a value in range [0, T] where T is the orbit period.
 func (o Elliptical) ElapsedSincePeriapsis(gravParam, trueAnomaly float64) float64 {
@@ -202,3 +208,14 @@ func (o Elliptical) Velocity(gravParam, trueAnomaly float64) (vRadial, vTangenti
 	vTangential = gdivh * (1 + e*cost) // Eqn (2.48)
 	return vRadial, vTangential
 }
+
+// NewEllipticalFromState returns the elliptical orbit of a body at position r [m] with velocity v [m/s]
+// around a world of gravitational parameter gravParam [m^3.s^-2]. It returns an error for unbound orbits.
+// See [NewElementsFromState] and [Elements.Orbit].
+func NewEllipticalFromState(r, v md3.Vec, gravParam float64) (Elliptical, error) {
+	el, err := NewElementsFromState(r, v, gravParam)
+	if err != nil {
+		return Elliptical{}, err
+	}
+	return el.Orbit()
+}
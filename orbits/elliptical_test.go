@@ -56,3 +56,20 @@ func TestElliptical_geocentric(t *testing.T) {
 		t.Errorf("wanted %f, got %f", wantTA, gotTrueAnomaly)
 	}
 }
+
+func TestCircular(t *testing.T) {
+	const r = 6700e3
+	o, err := NewCircular(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantV := math.Sqrt(earthGravParam / r)
+	vr, vt := o.Velocity(earthGravParam, 1)
+	if vr != 0 || !md1.EqualWithinAbs(vt, wantV, 1e-9) {
+		t.Errorf("wanted velocity (0, %f), got (%f, %f)", wantV, vr, vt)
+	}
+	wantPeriod := 2 * math.Pi * r / wantV
+	if T := o.Period(earthGravParam); !md1.EqualWithinAbs(T, wantPeriod, 1e-6) {
+		t.Errorf("wanted period %f, got %f", wantPeriod, T)
+	}
+}
@@ -0,0 +1,174 @@
+package orbits
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Gibbs returns the velocity [m/s] of a body at the second of three coplanar inertial positions r1, r2, r3 [m]
+// of the same orbit around a world of gravitational parameter gravParam [m^3.s^-2]. Positions should be
+// separated by more than a few degrees, use [HerrickGibbs] for closely spaced positions.
+// An error is returned if the positions are more than 1° from coplanar or collinear.
+// Curtis, Howard's Orbital Mechanics, Algorithm 5.1.
+func Gibbs(r1, r2, r3 md3.Vec, gravParam float64) (v2 md3.Vec, err error) {
+	if err := checkCoplanar(r1, r2, r3); err != nil {
+		return md3.Vec{}, err
+	}
+	n1, n2, n3 := md3.Norm(r1), md3.Norm(r2), md3.Norm(r3)
+	c12, c23, c31 := md3.Cross(r1, r2), md3.Cross(r2, r3), md3.Cross(r3, r1)
+	N := md3.Add(md3.Add(md3.Scale(n1, c23), md3.Scale(n2, c31)), md3.Scale(n3, c12))
+	D := md3.Add(md3.Add(c12, c23), c31)
+	S := md3.Add(md3.Add(md3.Scale(n2-n3, r1), md3.Scale(n3-n1, r2)), md3.Scale(n1-n2, r3))
+	ND := md3.Norm(N) * md3.Norm(D)
+	if ND == 0 || md3.Dot(N, D) <= 0 {
+		return md3.Vec{}, errors.New("gibbs positions do not define an orbit")
+	}
+	return md3.Scale(math.Sqrt(gravParam/ND), md3.Add(md3.Scale(1/n2, md3.Cross(D, r2)), S)), nil
+}
+
+// HerrickGibbs returns the velocity [m/s] of a body at the second of three inertial positions r1, r2, r3 [m]
+// observed at epoch times t1 < t2 < t3 [s] around a world of gravitational parameter gravParam [m^3.s^-2].
+// It approximates the orbit with a Taylor series and is accurate for positions separated by a few degrees or less.
+// An error is returned if the times are not increasing or the positions are more than 1° from coplanar.
+// Vallado, Fundamentals of Astrodynamics and Applications, Algorithm 55.
+func HerrickGibbs(r1, r2, r3 md3.Vec, t1, t2, t3, gravParam float64) (v2 md3.Vec, err error) {
+	if !(t1 < t2 && t2 < t3) {
+		return md3.Vec{}, errors.New("herrick-gibbs observation times not increasing")
+	}
+	if err := checkCoplanar(r1, r2, r3); err != nil {
+		return md3.Vec{}, err
+	}
+	dt21, dt31, dt32 := t2-t1, t3-t1, t3-t2
+	term := func(r md3.Vec) float64 {
+		n := md3.Norm(r)
+		return gravParam / (12 * n * n * n)
+	}
+	v2 = md3.Scale(-dt32*(1/(dt21*dt31)+term(r1)), r1)
+	v2 = md3.Add(v2, md3.Scale((dt32-dt21)*(1/(dt21*dt32)+term(r2)), r2))
+	v2 = md3.Add(v2, md3.Scale(dt21*(1/(dt32*dt31)+term(r3)), r3))
+	return v2, nil
+}
+
+// checkCoplanar returns an error if r1 is more than 1° away from the plane of r2 and r3.
+func checkCoplanar(r1, r2, r3 md3.Vec) error {
+	n23 := md3.Cross(r2, r3)
+	if md3.Norm(n23) == 0 || md3.Norm(r1) == 0 {
+		return errors.New("collinear positions do not define an orbit plane")
+	}
+	const maxAngle = math.Pi / 180
+	if angle := math.Asin(math.Abs(md3.Dot(md3.Unit(r1), md3.Unit(n23)))); angle > maxAngle {
+		return fmt.Errorf("positions not coplanar, %.3g° out of plane", angle*180/math.Pi)
+	}
+	return nil
+}
+
+// Gauss returns the inertial position [m] and velocity [m/s] of a body at the second of three angles-only
+// observations at epoch times t [s] around a world of gravitational parameter gravParam [m^3.s^-2].
+// los are the inertial unit vectors from the observer to the body and site the inertial positions of the
+// observer [m] at each observation. The initial solution from the truncated series of the Lagrange coefficients
+// is refined by iterating with the exact coefficients until the slant ranges converge. If the eighth order
+// polynomial of Gauss' method has several positive roots the largest is used.
+// Curtis, Howard's Orbital Mechanics, Algorithms 5.5 and 5.6.
+func Gauss(t [3]float64, los, site [3]md3.Vec, gravParam float64) (r2, v2 md3.Vec, err error) {
+	mu := gravParam
+	tau1, tau3 := t[0]-t[1], t[2]-t[1]
+	tau := tau3 - tau1
+	if !(tau1 < 0 && tau3 > 0) {
+		return md3.Vec{}, md3.Vec{}, errors.New("gauss observation times not increasing")
+	}
+	p := [3]md3.Vec{md3.Cross(los[1], los[2]), md3.Cross(los[0], los[2]), md3.Cross(los[0], los[1])}
+	D0 := md3.Dot(los[0], p[0])
+	if math.Abs(D0) < 1e-12 {
+		return md3.Vec{}, md3.Vec{}, errors.New("gauss lines of sight are coplanar")
+	}
+	var D [3][3]float64
+	for i := range D {
+		for j := range D[i] {
+			D[i][j] = md3.Dot(site[i], p[j])
+		}
+	}
+	A := (-D[0][1]*tau3/tau + D[1][1] + D[2][1]*tau1/tau) / D0
+	B := (D[0][1]*(tau3*tau3-tau*tau)*tau3/tau + D[2][1]*(tau*tau-tau1*tau1)*tau1/tau) / (6 * D0)
+	E := md3.Dot(site[1], los[1])
+	R2 := md3.Norm2(site[1])
+	a := -(A*A + 2*A*E + R2)
+	b := -2 * mu * B * (A + E)
+	c := -mu * mu * B * B
+	r, ok := gaussRoot(a, b, c)
+	if !ok {
+		return md3.Vec{}, md3.Vec{}, errors.New("gauss polynomial has no positive root")
+	}
+	r3 := r * r * r
+	rho := [3]float64{
+		((6*(D[2][0]*tau1/tau3+D[1][0]*tau/tau3)*r3+mu*D[2][0]*(tau*tau-tau1*tau1)*tau1/tau3)/(6*r3+mu*(tau*tau-tau3*tau3)) - D[0][0]) / D0,
+		A + mu*B/r3,
+		((6*(D[0][2]*tau3/tau1-D[1][2]*tau/tau1)*r3+mu*D[0][2]*(tau*tau-tau3*tau3)*tau3/tau1)/(6*r3+mu*(tau*tau-tau1*tau1)) - D[2][2]) / D0,
+	}
+	f1 := 1 - mu*tau1*tau1/(2*r3)
+	f3 := 1 - mu*tau3*tau3/(2*r3)
+	g1 := tau1 - mu*tau1*tau1*tau1/(6*r3)
+	g3 := tau3 - mu*tau3*tau3*tau3/(6*r3)
+	var pos [3]md3.Vec
+	velocity := func() md3.Vec {
+		for i := range pos {
+			pos[i] = md3.Add(site[i], md3.Scale(rho[i], los[i]))
+		}
+		return md3.Scale(1/(f1*g3-f3*g1), md3.Sub(md3.Scale(f1, pos[2]), md3.Scale(f3, pos[0])))
+	}
+	v2 = velocity()
+
+	// Iterative improvement with exact Lagrange coefficients.
+	const maxIter, tol = 100, 1e-10
+	for i := 0; i < maxIter; i++ {
+		nf1, ng1, err1 := lagrangeCoefficients(pos[1], v2, tau1, mu)
+		nf3, ng3, err3 := lagrangeCoefficients(pos[1], v2, tau3, mu)
+		if err1 != nil || err3 != nil {
+			return md3.Vec{}, md3.Vec{}, errors.New("gauss iterative improvement failed to propagate")
+		}
+		// Averaging with the previous coefficients damps oscillations.
+		f1, g1, f3, g3 = (f1+nf1)/2, (g1+ng1)/2, (f3+nf3)/2, (g3+ng3)/2
+		den := f1*g3 - f3*g1
+		c1, c3 := g3/den, -g1/den
+		prev := rho
+		rho = [3]float64{
+			(-D[0][0] + D[1][0]/c1 - c3/c1*D[2][0]) / D0,
+			(-c1*D[0][1] + D[1][1] - c3*D[2][1]) / D0,
+			(-c1/c3*D[0][2] + D[1][2]/c3 - D[2][2]) / D0,
+		}
+		v2 = velocity()
+		converged := true
+		for j := range rho {
+			converged = converged && math.Abs(rho[j]-prev[j]) <= tol*math.Abs(rho[j])
+		}
+		if converged {
+			return pos[1], v2, nil
+		}
+	}
+	return md3.Vec{}, md3.Vec{}, errors.New("gauss iterative improvement did not converge")
+}
+
+// gaussRoot returns the largest positive root of x^8 + a*x^6 + b*x^3 + c with Newton's method
+// started above Fujiwara's bound on the roots.
+func gaussRoot(a, b, c float64) (x float64, ok bool) {
+	F := func(x float64) (f, df float64) {
+		x2, x3 := x*x, x*x*x
+		return x2*x3*x3 + a*x3*x3 + b*x3 + c, 8*x*x3*x3 + 6*a*x2*x3 + 3*b*x2
+	}
+	x = 2 * math.Max(math.Sqrt(math.Abs(a)), math.Max(math.Pow(math.Abs(b), 1./5), math.Pow(math.Abs(c)/2, 1./8)))
+	const maxIter = 200
+	for i := 0; i < maxIter; i++ {
+		f, df := F(x)
+		if df == 0 {
+			return 0, false
+		}
+		dx := f / df
+		x -= dx
+		if math.Abs(dx) <= 1e-14*x {
+			return x, x > 0
+		}
+	}
+	return 0, false
+}
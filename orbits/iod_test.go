@@ -0,0 +1,131 @@
+package orbits
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestElementsRoundTrip(t *testing.T) {
+	mu := earthGravParam
+	want := Elements{
+		SemiMajorAxis: 10000e3,
+		Eccentricity:  0.1,
+		Inclination:   30 * math.Pi / 180,
+		RAAN:          270 * math.Pi / 180,
+		ArgPeriapsis:  90 * math.Pi / 180,
+		TrueAnomaly:   45 * math.Pi / 180,
+	}
+	r, v := want.State(mu)
+	got, err := NewElementsFromState(r, v, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotv := [6]float64{got.SemiMajorAxis, got.Eccentricity, got.Inclination, got.RAAN, got.ArgPeriapsis, got.TrueAnomaly}
+	wantv := [6]float64{want.SemiMajorAxis, want.Eccentricity, want.Inclination, want.RAAN, want.ArgPeriapsis, want.TrueAnomaly}
+	for i := range wantv {
+		if math.Abs(gotv[i]-wantv[i]) > 1e-9*math.Max(1, math.Abs(wantv[i])) {
+			t.Errorf("element %d want %g, got %g", i, wantv[i], gotv[i])
+		}
+	}
+	orbit, err := got.Orbit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := orbit.Periapsis() - 9000e3; math.Abs(d) > 1e-3 {
+		t.Errorf("periapsis error %g", d)
+	}
+	// Circular equatorial orbits measure the true anomaly from the X axis.
+	circ, err := NewElementsFromState(md3.Vec{Y: 7000e3}, md3.Vec{X: -math.Sqrt(mu / 7000e3)}, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(circ.TrueAnomaly-math.Pi/2) > 1e-9 || circ.Eccentricity > 1e-9 || circ.RAAN != 0 {
+		t.Errorf("unexpected circular equatorial elements %+v", circ)
+	}
+	// Escape velocity and beyond is not bound.
+	if _, err := NewEllipticalFromState(md3.Vec{Y: 7000e3}, md3.Vec{X: -1.5 * math.Sqrt(2*mu/7000e3)}, mu); err == nil {
+		t.Error("expected error for hyperbolic orbit")
+	}
+}
+
+func TestGibbs(t *testing.T) {
+	// Curtis Example 5.1.
+	const mu = 398600e9
+	r1 := md3.Vec{X: -294.32e3, Y: 4265.1e3, Z: 5986.7e3}
+	r2 := md3.Vec{X: -1365.5e3, Y: 3637.6e3, Z: 6346.8e3}
+	r3 := md3.Vec{X: -2940.3e3, Y: 2473.7e3, Z: 6555.8e3}
+	v2, err := Gibbs(r1, r2, r3, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := md3.Vec{X: -6217.4, Y: -4012.2, Z: 1599.0}
+	if !md3.EqualElem(v2, want, 1) {
+		t.Errorf("want %v, got %v", want, v2)
+	}
+	if _, err := Gibbs(r1, r2, md3.Add(r3, md3.Vec{Z: 1000e3}), mu); err == nil {
+		t.Error("expected error for non coplanar positions")
+	}
+}
+
+func TestHerrickGibbs(t *testing.T) {
+	mu := earthGravParam
+	el := Elements{SemiMajorAxis: 7200e3, Eccentricity: 0.01, Inclination: 1, RAAN: 2, ArgPeriapsis: 0.5, TrueAnomaly: 1}
+	r0, v0 := el.State(mu)
+	ts := [3]float64{-30, 0, 40}
+	var r [3]md3.Vec
+	for i, dt := range ts {
+		r[i], _, _ = KeplerPropagate(r0, v0, dt, mu)
+	}
+	v2, err := HerrickGibbs(r[0], r[1], r[2], ts[0], ts[1], ts[2], mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := md3.Norm(md3.Sub(v2, v0)); d > 1e-3 {
+		t.Errorf("velocity error %gm/s", d)
+	}
+}
+
+func TestGauss(t *testing.T) {
+	mu := earthGravParam
+	const earthRadius, omegaEarth, lat = 6378e3, 7.292115e-5, 40 * math.Pi / 180
+	el := Elements{
+		SemiMajorAxis: 10000e3,
+		Eccentricity:  0.1,
+		Inclination:   30 * math.Pi / 180,
+		RAAN:          270 * math.Pi / 180,
+		ArgPeriapsis:  90 * math.Pi / 180,
+		TrueAnomaly:   45 * math.Pi / 180,
+	}
+	r0, v0 := el.State(mu)
+	ts := [3]float64{-118.1, 0, 119.5}
+	var los, site [3]md3.Vec
+	for i, dt := range ts {
+		r, _, err := KeplerPropagate(r0, v0, dt, mu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slat, clat := math.Sincos(lat)
+		slst, clst := math.Sincos(0.8 + omegaEarth*dt) // Local sidereal time.
+		site[i] = md3.Scale(earthRadius, md3.Vec{X: clat * clst, Y: clat * slst, Z: slat})
+		los[i] = md3.Unit(md3.Sub(r, site[i]))
+	}
+	r2, v2, err := Gauss(ts, los, site, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := md3.Norm(md3.Sub(r2, r0)); d > 1 {
+		t.Errorf("position error %gm", d)
+	}
+	if d := md3.Norm(md3.Sub(v2, v0)); d > 1e-3 {
+		t.Errorf("velocity error %gm/s", d)
+	}
+	got, err := NewElementsFromState(r2, v2, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got.Eccentricity-0.1) > 1e-6 || math.Abs(got.Inclination-el.Inclination) > 1e-6 {
+		t.Errorf("unexpected elements %+v", got)
+	}
+}
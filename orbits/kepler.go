@@ -0,0 +1,98 @@
+package orbits
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// KeplerPropagate returns the position [m] and velocity [m/s] of a body under the point mass gravity of a
+// world of gravitational parameter gravParam [m^3.s^-2] dt seconds after it was at position r0 with velocity v0.
+// dt may be negative. It is valid for elliptic, parabolic and hyperbolic orbits.
+// Curtis, Howard's Orbital Mechanics, universal variable formulation, Algorithms 3.3 and 3.4.
+func KeplerPropagate(r0, v0 md3.Vec, dt, gravParam float64) (r, v md3.Vec, err error) {
+	chi, alpha, err := universalAnomaly(r0, v0, dt, gravParam)
+	if err != nil {
+		return r0, v0, err
+	}
+	sqrtMu := math.Sqrt(gravParam)
+	r0n := md3.Norm(r0)
+	// Lagrange coefficients.
+	z := alpha * chi * chi
+	C, S := stumpffC(z), stumpffS(z)
+	f := 1 - chi*chi/r0n*C
+	g := dt - chi*chi*chi/sqrtMu*S
+	r = md3.Add(md3.Scale(f, r0), md3.Scale(g, v0))
+	rn := md3.Norm(r)
+	fdot := sqrtMu / (rn * r0n) * (z*S - 1) * chi
+	gdot := 1 - chi*chi/rn*C
+	v = md3.Add(md3.Scale(fdot, r0), md3.Scale(gdot, v0))
+	return r, v, nil
+}
+
+// lagrangeCoefficients returns the Lagrange coefficients f and g such that the position dt seconds
+// after a body is at r0 with velocity v0 is f*r0 + g*v0.
+func lagrangeCoefficients(r0, v0 md3.Vec, dt, gravParam float64) (f, g float64, err error) {
+	chi, alpha, err := universalAnomaly(r0, v0, dt, gravParam)
+	if err != nil {
+		return 0, 0, err
+	}
+	z := alpha * chi * chi
+	f = 1 - chi*chi/md3.Norm(r0)*stumpffC(z)
+	g = dt - chi*chi*chi/math.Sqrt(gravParam)*stumpffS(z)
+	return f, g, nil
+}
+
+// universalAnomaly solves universal Kepler's equation for the universal anomaly chi [m^0.5] dt seconds
+// after a body is at r0 with velocity v0 with Newton's method. alpha is the reciprocal of the semimajor axis [m^-1].
+func universalAnomaly(r0, v0 md3.Vec, dt, gravParam float64) (chi, alpha float64, err error) {
+	sqrtMu := math.Sqrt(gravParam)
+	r0n := md3.Norm(r0)
+	vr0 := md3.Dot(r0, v0) / r0n
+	alpha = 2/r0n - md3.Norm2(v0)/gravParam
+	chi = sqrtMu * math.Abs(alpha) * dt
+	if alpha <= 0 || chi == 0 {
+		chi = sqrtMu * dt / r0n
+	}
+	const maxIter, tol = 50, 1e-12
+	converged := false
+	for i := 0; i < maxIter && !converged; i++ {
+		z := alpha * chi * chi
+		C, S := stumpffC(z), stumpffS(z)
+		chi2 := chi * chi
+		f := r0n*vr0/sqrtMu*chi2*C + (1-alpha*r0n)*chi2*chi*S + r0n*chi - sqrtMu*dt
+		df := r0n*vr0/sqrtMu*chi*(1-z*S) + (1-alpha*r0n)*chi2*C + r0n
+		ratio := f / df
+		chi -= ratio
+		converged = math.Abs(ratio) <= tol*math.Max(1, math.Abs(chi))
+	}
+	if !converged || math.IsNaN(chi) {
+		return 0, 0, errors.New("universal Kepler equation did not converge")
+	}
+	return chi, alpha, nil
+}
+
+// stumpffS returns the Stumpff function S(z).
+func stumpffS(z float64) float64 {
+	switch {
+	case z > 1e-6:
+		sz := math.Sqrt(z)
+		return (sz - math.Sin(sz)) / (sz * sz * sz)
+	case z < -1e-6:
+		sz := math.Sqrt(-z)
+		return (math.Sinh(sz) - sz) / (sz * sz * sz)
+	}
+	return 1./6 - z/120 + z*z/5040
+}
+
+// stumpffC returns the Stumpff function C(z).
+func stumpffC(z float64) float64 {
+	switch {
+	case z > 1e-6:
+		return (1 - math.Cos(math.Sqrt(z))) / z
+	case z < -1e-6:
+		return (math.Cosh(math.Sqrt(-z)) - 1) / -z
+	}
+	return 0.5 - z/24 + z*z/720
+}
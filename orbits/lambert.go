@@ -0,0 +1,233 @@
+package orbits
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// LambertSolution is a transfer orbit arc between two positions found by [Lambert].
+type LambertSolution struct {
+	R1, R2 md3.Vec // Departure and arrival positions [m].
+	V1, V2 md3.Vec // Departure and arrival velocities [m/s].
+	// Revolutions is the number of complete revolutions of the transfer.
+	Revolutions int
+}
+
+// Orbit returns the elliptical transfer orbit. It returns an error for hyperbolic transfers.
+func (s LambertSolution) Orbit(gravParam float64) (Elliptical, error) {
+	return NewEllipticalFromState(s.R1, s.V1, gravParam)
+}
+
+// Lambert solves Lambert's problem: it finds the orbits connecting position r1 to position r2 [m]
+// in time of flight tof [s] around a world of gravitational parameter gravParam [m^3.s^-2].
+// The short way transfer sweeps an angle smaller than pi in the direction of r1×r2 and the long way
+// transfer sweeps the complementary angle in the opposite direction. Solutions with up to maxRevs complete
+// revolutions are returned in increasing order of revolutions: one for zero revolutions and two for each
+// feasible multi-revolution case, the left branch solution before the right branch solution.
+// It returns an error if no solution converges.
+// It uses the algorithm of Izzo - Revisiting Lambert's problem (2015).
+func Lambert(r1, r2 md3.Vec, tof, gravParam float64, longWay bool, maxRevs int) ([]LambertSolution, error) {
+	if !(tof > 0) || !(gravParam > 0) {
+		return nil, errors.New("lambert requires positive time of flight and gravitational parameter")
+	} else if maxRevs < 0 {
+		return nil, errors.New("negative lambert revolutions")
+	}
+	r1n, r2n := md3.Norm(r1), md3.Norm(r2)
+	c := md3.Norm(md3.Sub(r2, r1))
+	s := (r1n + r2n + c) / 2
+	ir1, ir2 := md3.Scale(1/r1n, r1), md3.Scale(1/r2n, r2)
+	h := md3.Cross(ir1, ir2)
+	if md3.Norm(h) < 1e-12 {
+		return nil, errors.New("lambert positions are collinear, transfer plane undefined")
+	}
+	ih := md3.Unit(h)
+	lambda := math.Sqrt(1 - c/s)
+	it1, it2 := md3.Cross(ih, ir1), md3.Cross(ih, ir2)
+	if longWay {
+		lambda = -lambda
+		it1, it2 = md3.Scale(-1, it1), md3.Scale(-1, it2)
+	}
+	T := math.Sqrt(2*gravParam/(s*s*s)) * tof
+	xs, revs := lambertSolveX(lambda, T, maxRevs)
+	if len(xs) == 0 {
+		return nil, errors.New("lambert zero revolution solution did not converge")
+	}
+
+	gamma := math.Sqrt(gravParam * s / 2)
+	rho := (r1n - r2n) / c
+	sigma := math.Sqrt(1 - rho*rho)
+	sols := make([]LambertSolution, len(xs))
+	for i, x := range xs {
+		y := math.Sqrt(1 - lambda*lambda + lambda*lambda*x*x)
+		vr1 := gamma * ((lambda*y - x) - rho*(lambda*y+x)) / r1n
+		vr2 := -gamma * ((lambda*y - x) + rho*(lambda*y+x)) / r2n
+		vt := gamma * sigma * (y + lambda*x)
+		sols[i] = LambertSolution{
+			R1:          r1,
+			R2:          r2,
+			V1:          md3.Add(md3.Scale(vr1, ir1), md3.Scale(vt/r1n, it1)),
+			V2:          md3.Add(md3.Scale(vr2, ir2), md3.Scale(vt/r2n, it2)),
+			Revolutions: revs[i],
+		}
+	}
+	return sols, nil
+}
+
+// lambertSolveX returns the solutions x of the non-dimensional time of flight equation T(x) = T
+// and their number of revolutions.
+func lambertSolveX(lambda, T float64, maxRevs int) (xs []float64, revs []int) {
+	l2 := lambda * lambda
+	l3 := l2 * lambda
+	// Maximum number of revolutions for which a solution exists.
+	mMax := int(T / math.Pi)
+	T00 := math.Acos(lambda) + lambda*math.Sqrt(1-l2)
+	T0 := T00 + float64(mMax)*math.Pi
+	T1 := 2. / 3 * (1 - l3)
+	if mMax > 0 && T < T0 {
+		// Halley iterations to find the minimum time of flight with mMax revolutions.
+		xOld, Tmin := 0.0, T0
+		for i := 0; i < 12; i++ {
+			dT, ddT, dddT := lambertDerivatives(lambda, xOld, Tmin)
+			xNew := xOld
+			if dT != 0 {
+				xNew = xOld - dT*ddT/(ddT*ddT-dT*dddT/2)
+			}
+			if math.Abs(xOld-xNew) < 1e-13 {
+				break
+			}
+			Tmin = lambertTOF(lambda, xNew, mMax)
+			xOld = xNew
+		}
+		if Tmin > T {
+			mMax--
+		}
+	}
+	mMax = min(mMax, maxRevs)
+
+	// Zero revolution initial guess.
+	var x0 float64
+	switch {
+	case T >= T00:
+		x0 = -(T - T00) / (T - T00 + 4)
+	case T <= T1:
+		x0 = T1*(T1-T)/(2./5*(1-l2*l3)*T) + 1
+	default:
+		x0 = math.Pow(T/T00, math.Ln2/math.Log(T1/T00)) - 1
+	}
+	if x, ok := lambertHouseholder(lambda, T, x0, 0); ok {
+		xs, revs = append(xs, x), append(revs, 0)
+	}
+	for m := 1; m <= mMax; m++ {
+		mpi := float64(m) * math.Pi
+		tmp := math.Pow((mpi+math.Pi)/(8*T), 2./3)
+		xl := (tmp - 1) / (tmp + 1)
+		tmp = math.Pow(8*T/mpi, 2./3)
+		xr := (tmp - 1) / (tmp + 1)
+		for _, guess := range [2]float64{xl, xr} {
+			if x, ok := lambertHouseholder(lambda, T, guess, m); ok {
+				xs, revs = append(xs, x), append(revs, m)
+			}
+		}
+	}
+	return xs, revs
+}
+
+// lambertHouseholder solves T(x) = T for m revolutions with third order Householder iterations.
+func lambertHouseholder(lambda, T, x0 float64, m int) (float64, bool) {
+	const maxIter, tol = 15, 1e-13
+	x := x0
+	for i := 0; i < maxIter; i++ {
+		tof := lambertTOF(lambda, x, m)
+		dT, ddT, dddT := lambertDerivatives(lambda, x, tof)
+		delta := tof - T
+		dT2 := dT * dT
+		xNew := x - delta*(dT2-delta*ddT/2)/(dT*(dT2-delta*ddT)+dddT*delta*delta/6)
+		if math.IsNaN(xNew) {
+			return 0, false
+		}
+		converged := math.Abs(x-xNew) < tol
+		x = xNew
+		if converged {
+			return x, true
+		}
+	}
+	return x, false
+}
+
+// lambertDerivatives returns the first three derivatives of the non-dimensional time of flight T at x.
+func lambertDerivatives(lambda, x, T float64) (dT, ddT, dddT float64) {
+	l2 := lambda * lambda
+	l3 := l2 * lambda
+	umx2 := 1 - x*x
+	y := math.Sqrt(1 - l2*umx2)
+	y2 := y * y
+	y3 := y2 * y
+	dT = (3*T*x - 2 + 2*l3*x/y) / umx2
+	ddT = (3*T + 5*x*dT + 2*(1-l2)*l3/y3) / umx2
+	dddT = (7*x*ddT + 8*dT - 6*(1-l2)*l2*l3*x/y3/y2) / umx2
+	return dT, ddT, dddT
+}
+
+// lambertTOF returns the non-dimensional time of flight at x for m revolutions.
+func lambertTOF(lambda, x float64, m int) float64 {
+	const battin, lagrange = 0.01, 0.2
+	dist := math.Abs(x - 1)
+	if dist < lagrange && dist > battin {
+		return lambertTOFLagrange(lambda, x, m)
+	}
+	K := lambda * lambda
+	E := x*x - 1
+	rho := math.Abs(E)
+	z := math.Sqrt(1 + K*E)
+	if dist < battin {
+		// Battin's series expression avoids the singularity at x=1.
+		eta := z - lambda*x
+		S1 := 0.5 * (1 - lambda - x*eta)
+		Q := 4. / 3 * hypergeometricF(S1, 1e-11)
+		return (eta*eta*eta*Q+4*lambda*eta)/2 + float64(m)*math.Pi/math.Pow(rho, 1.5)
+	}
+	// Lancaster's expression.
+	y := math.Sqrt(rho)
+	g := x*z - lambda*E
+	var d float64
+	if E < 0 {
+		d = float64(m)*math.Pi + math.Acos(g)
+	} else {
+		f := y * (z - lambda*x)
+		d = math.Log(f + g)
+	}
+	return (x - lambda*z - d/y) / E
+}
+
+// lambertTOFLagrange returns the non-dimensional time of flight at x using Lagrange's expression.
+func lambertTOFLagrange(lambda, x float64, m int) float64 {
+	a := 1 / (1 - x*x)
+	if a > 0 {
+		// Ellipse.
+		alpha := 2 * math.Acos(x)
+		beta := 2 * math.Asin(math.Sqrt(lambda*lambda/a))
+		if lambda < 0 {
+			beta = -beta
+		}
+		return a * math.Sqrt(a) * ((alpha - math.Sin(alpha)) - (beta - math.Sin(beta)) + 2*math.Pi*float64(m)) / 2
+	}
+	// Hyperbola.
+	alpha := 2 * math.Acosh(x)
+	beta := 2 * math.Asinh(math.Sqrt(-lambda*lambda/a))
+	if lambda < 0 {
+		beta = -beta
+	}
+	return -a * math.Sqrt(-a) * ((beta - math.Sinh(beta)) - (alpha - math.Sinh(alpha))) / 2
+}
+
+// hypergeometricF returns the Gauss hypergeometric function 2F1(3, 1, 5/2, z) to tolerance tol.
+func hypergeometricF(z, tol float64) float64 {
+	sj, cj := 1.0, 1.0
+	for j := 0.0; math.Abs(cj) > tol; j++ {
+		cj = cj * (3 + j) * (1 + j) / (2.5 + j) * z / (j + 1)
+		sj += cj
+	}
+	return sj
+}
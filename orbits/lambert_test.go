@@ -0,0 +1,116 @@
+package orbits
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestLambertCurtis(t *testing.T) {
+	// Curtis Example 5.2.
+	const mu = 398600e9
+	r1 := md3.Vec{X: 5000e3, Y: 10000e3, Z: 2100e3}
+	r2 := md3.Vec{X: -14600e3, Y: 2500e3, Z: 7000e3}
+	sols, err := Lambert(r1, r2, 3600, mu, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sols) != 1 {
+		t.Fatalf("want one solution, got %d", len(sols))
+	}
+	wantV1 := md3.Vec{X: -5992.5, Y: 1925.4, Z: 3245.6}
+	wantV2 := md3.Vec{X: -3312.5, Y: -4196.6, Z: -385.29}
+	if !md3.EqualElem(sols[0].V1, wantV1, 0.1) || !md3.EqualElem(sols[0].V2, wantV2, 0.1) {
+		t.Errorf("want v1=%v v2=%v, got v1=%v v2=%v", wantV1, wantV2, sols[0].V1, sols[0].V2)
+	}
+	orbit, err := sols[0].Orbit(mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Curtis Example 5.3 transfer orbit eccentricity.
+	if math.Abs(orbit.Eccentricity()-0.4335) > 1e-4 {
+		t.Errorf("transfer orbit eccentricity want 0.4335, got %g", orbit.Eccentricity())
+	}
+}
+
+func TestLambertMultiRevolution(t *testing.T) {
+	mu := earthGravParam
+	r1 := md3.Vec{X: 7000e3, Y: 500e3, Z: -300e3}
+	r2 := md3.Vec{X: -2000e3, Y: 8000e3, Z: 1000e3}
+	for _, test := range []struct {
+		tof     float64
+		longWay bool
+		maxRevs int
+		want    int
+	}{
+		{tof: 1500, want: 1},
+		{tof: 1500, longWay: true, want: 1},
+		{tof: 4000, longWay: true, want: 1},
+		{tof: 20000, maxRevs: 3, want: 7},
+		{tof: 20000, longWay: true, maxRevs: 1, want: 3},
+		{tof: 200, want: 1}, // Hyperbolic.
+	} {
+		sols, err := Lambert(r1, r2, test.tof, mu, test.longWay, test.maxRevs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sols) != test.want {
+			t.Errorf("tof=%g longWay=%v: want %d solutions, got %d", test.tof, test.longWay, test.want, len(sols))
+		}
+		for _, s := range sols {
+			r, v, err := KeplerPropagate(r1, s.V1, test.tof, mu)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := md3.Norm(md3.Sub(r, r2)); d > 1 {
+				t.Errorf("tof=%g longWay=%v revs=%d: arrival misses by %gm", test.tof, test.longWay, s.Revolutions, d)
+			}
+			if d := md3.Norm(md3.Sub(v, s.V2)); d > 1e-3 {
+				t.Errorf("tof=%g longWay=%v revs=%d: arrival velocity differs by %gm/s", test.tof, test.longWay, s.Revolutions, d)
+			}
+			// Short way transfers move in the direction of r1×r2.
+			h := md3.Dot(md3.Cross(r1, s.V1), md3.Cross(r1, r2))
+			if (h > 0) == test.longWay {
+				t.Errorf("tof=%g longWay=%v: transfer in wrong direction", test.tof, test.longWay)
+			}
+		}
+	}
+	if _, err := Lambert(r1, md3.Scale(2, r1), 1000, mu, false, 0); err == nil {
+		t.Error("expected error for collinear positions")
+	}
+	if _, err := Lambert(r1, md3.Vec{X: math.NaN(), Y: r1.X}, 1000, mu, false, 0); err == nil {
+		t.Error("expected error when no solution converges")
+	}
+}
+
+func TestKeplerPropagate(t *testing.T) {
+	mu := earthGravParam
+	r0, v0 := md3.Vec{X: 7000e3}, md3.Vec{Y: 7000, Z: 3000}
+	o, err := NewEllipticalFromState(r0, v0, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	period := o.Period(mu)
+	r, v, err := KeplerPropagate(r0, v0, period, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !md3.EqualElem(r, r0, 1e-3) || !md3.EqualElem(v, v0, 1e-6) {
+		t.Errorf("orbit not closed after one period: %v %v", r, v)
+	}
+	// Forward then backward propagation returns to the start.
+	r, v, _ = KeplerPropagate(r0, v0, 1234, mu)
+	r, v, _ = KeplerPropagate(r, v, -1234, mu)
+	if !md3.EqualElem(r, r0, 1e-3) || !md3.EqualElem(v, v0, 1e-6) {
+		t.Errorf("round trip mismatch: %v %v", r, v)
+	}
+	// Energy is conserved on hyperbolic orbits.
+	vh := md3.Vec{Y: 12000}
+	r, v, _ = KeplerPropagate(r0, vh, 5000, mu)
+	e0 := md3.Norm2(vh)/2 - mu/md3.Norm(r0)
+	e1 := md3.Norm2(v)/2 - mu/md3.Norm(r)
+	if math.Abs(e1-e0) > 1e-6*math.Abs(e0) {
+		t.Errorf("hyperbolic energy not conserved: %g %g", e0, e1)
+	}
+}
@@ -0,0 +1,215 @@
+package orbits
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Burn is an impulsive change of velocity of a maneuver.
+type Burn struct {
+	// T is the time of the burn since the start of the maneuver [s].
+	T float64
+	// DeltaV is the change of velocity [m/s] in the RSW frame of the body just before the burn:
+	// R is radially outward, W is along the orbit's angular momentum and S = W×R is along track.
+	DeltaV md3.Vec
+}
+
+// Inertial returns the burn's change of velocity in inertial frame [m/s] for a body at inertial
+// position r with velocity v just before the burn.
+func (b Burn) Inertial(r, v md3.Vec) md3.Vec {
+	return md3.MulMatVecTrans(TRSW(r, v), b.DeltaV)
+}
+
+// TRSW returns the rotation tensor of RSW wrt inertial coordinates of a body at inertial
+// position r with velocity v. RSW vectors are converted to inertial with md3.MulMatVecTrans(TRSW, vRSW).
+func TRSW(r, v md3.Vec) md3.Mat3 {
+	R := md3.Unit(r)
+	W := md3.Unit(md3.Cross(r, v))
+	S := md3.Cross(W, R)
+	return md3.NewMat3([]float64{
+		R.X, R.Y, R.Z,
+		S.X, S.Y, S.Z,
+		W.X, W.Y, W.Z,
+	})
+}
+
+// Maneuver is a sequence of impulsive burns transferring a body between orbits.
+type Maneuver struct {
+	Burns []Burn
+	// Transfer are the intermediate orbits flown between consecutive burns.
+	Transfer []Elliptical
+}
+
+// DeltaV returns the sum of the magnitudes of the burns [m/s].
+func (m Maneuver) DeltaV() (dv float64) {
+	for _, b := range m.Burns {
+		dv += md3.Norm(b.DeltaV)
+	}
+	return dv
+}
+
+// Duration returns the time from the start of the maneuver to its last burn [s].
+func (m Maneuver) Duration() float64 {
+	if len(m.Burns) == 0 {
+		return 0
+	}
+	return m.Burns[len(m.Burns)-1].T
+}
+
+// Hohmann returns the two burn Hohmann transfer between coaxial orbits from and to sharing the direction of periapsis.
+// When raising the orbit the maneuver starts at the periapsis of from and ends at the apoapsis of to.
+// When lowering the orbit it starts at the apoapsis of from and ends at the periapsis of to.
+// gravParam is the gravitational parameter of the world [m^3.s^-2].
+func Hohmann(from, to Elliptical, gravParam float64) (Maneuver, error) {
+	return HohmannPlaneChange(from, to, 0, gravParam)
+}
+
+// HohmannPlaneChange returns a [Hohmann] transfer that also rotates the orbit plane by angle [rad] about the
+// position vector of the first burn, which must be a node of the final orbit. The plane change is split between
+// both burns to minimize the total change of velocity, most of it is done at the slower of the two apsides.
+func HohmannPlaneChange(from, to Elliptical, angle, gravParam float64) (Maneuver, error) {
+	r1, r2, err := hohmannRadii(from, to)
+	if err != nil {
+		return Maneuver{}, err
+	}
+	transfer, err := newEllipticalFromApsides(r1, r2)
+	if err != nil {
+		return Maneuver{}, err
+	}
+	v1 := visViva(gravParam, r1, from)
+	vt1 := visViva(gravParam, r1, transfer)
+	vt2 := visViva(gravParam, r2, transfer)
+	v2 := visViva(gravParam, r2, to)
+	// Golden section search of the plane change done at the first burn.
+	cost := func(alpha float64) float64 {
+		return combinedDeltaV(v1, vt1, alpha) + combinedDeltaV(vt2, v2, angle-alpha)
+	}
+	alpha := goldenSection(cost, math.Min(0, angle), math.Max(0, angle), 1e-12)
+	return Maneuver{
+		Burns: []Burn{
+			{T: 0, DeltaV: tangentialBurn(v1, vt1, alpha)},
+			// Rotating the plane about the first burn position needs the opposite W sense half an orbit later.
+			{T: transfer.Period(gravParam) / 2, DeltaV: tangentialBurn(vt2, v2, -(angle - alpha))},
+		},
+		Transfer: []Elliptical{transfer},
+	}, nil
+}
+
+// BiElliptic returns the three burn bi-elliptic transfer between coaxial orbits from and to through an intermediate
+// apsis at radius rb [m] which must be beyond both orbits. It starts at the periapsis of from and ends at the apoapsis
+// of to. It needs less change of velocity than [Hohmann] when the ratio of final to initial radii is large.
+func BiElliptic(from, to Elliptical, rb, gravParam float64) (Maneuver, error) {
+	r1, r2 := from.Periapsis(), to.Apoapsis()
+	if rb < from.Apoapsis() || rb < to.Apoapsis() {
+		return Maneuver{}, fmt.Errorf("bi-elliptic intermediate radius %.5gkm within initial or final orbit", rb/1e3)
+	}
+	t1, err := newEllipticalFromApsides(r1, rb)
+	if err != nil {
+		return Maneuver{}, err
+	}
+	t2, err := newEllipticalFromApsides(r2, rb)
+	if err != nil {
+		return Maneuver{}, err
+	}
+	tb := t1.Period(gravParam) / 2
+	return Maneuver{
+		Burns: []Burn{
+			{T: 0, DeltaV: tangentialBurn(visViva(gravParam, r1, from), visViva(gravParam, r1, t1), 0)},
+			{T: tb, DeltaV: tangentialBurn(visViva(gravParam, rb, t1), visViva(gravParam, rb, t2), 0)},
+			{T: tb + t2.Period(gravParam)/2, DeltaV: tangentialBurn(visViva(gravParam, r2, t2), visViva(gravParam, r2, to), 0)},
+		},
+		Transfer: []Elliptical{t1, t2},
+	}, nil
+}
+
+// PlaneChange returns the single burn that rotates the orbit plane of o by angle [rad] about the position vector of
+// a body at trueAnomaly [rad] without changing the shape of the orbit. Only the along track velocity is rotated so
+// the burn is cheapest where the body is slowest. Positive angles rotate the velocity towards W.
+func PlaneChange(o Elliptical, trueAnomaly, angle, gravParam float64) Maneuver {
+	_, vt := o.Velocity(gravParam, trueAnomaly) // Radial velocity is along the rotation axis.
+	return Maneuver{Burns: []Burn{{T: 0, DeltaV: tangentialBurn(vt, vt, angle)}}}
+}
+
+// Phasing returns the two burn phasing maneuver that lets a body at the periapsis of o rendezvous with a target in the
+// same orbit that leads it by lead seconds. The body flies revs revolutions of a phasing orbit of period T-lead/revs
+// where T is the period of o and returns to the periapsis of o together with the target. A negative lead means the
+// target trails the body.
+func Phasing(o Elliptical, lead float64, revs int, gravParam float64) (Maneuver, error) {
+	if revs < 1 {
+		return Maneuver{}, errors.New("phasing requires at least one revolution")
+	}
+	T := o.Period(gravParam) - lead/float64(revs)
+	if !(T > 0) {
+		return Maneuver{}, errors.New("phasing orbit period must be positive, increase revolutions")
+	}
+	rp := o.Periapsis()
+	a := math.Cbrt(gravParam * T * T / (4 * math.Pi * math.Pi))
+	phasing, err := newEllipticalFromApsides(rp, 2*a-rp)
+	if err != nil {
+		return Maneuver{}, err
+	}
+	v, vph := visViva(gravParam, rp, o), visViva(gravParam, rp, phasing)
+	return Maneuver{
+		Burns: []Burn{
+			{T: 0, DeltaV: tangentialBurn(v, vph, 0)},
+			{T: float64(revs) * T, DeltaV: tangentialBurn(vph, v, 0)},
+		},
+		Transfer: []Elliptical{phasing},
+	}, nil
+}
+
+// hohmannRadii returns the departure and arrival radii of a Hohmann transfer.
+func hohmannRadii(from, to Elliptical) (r1, r2 float64, err error) {
+	if to.a() >= from.a() {
+		r1, r2 = from.Periapsis(), to.Apoapsis()
+	} else {
+		r1, r2 = from.Apoapsis(), to.Periapsis()
+	}
+	if r1 <= 0 || r2 <= 0 {
+		return 0, 0, errors.New("hohmann transfer between degenerate orbits")
+	}
+	return r1, r2, nil
+}
+
+// newEllipticalFromApsides returns the orbit with apsides at radii r1 and r2 in any order.
+func newEllipticalFromApsides(r1, r2 float64) (Elliptical, error) {
+	return NewElliptical(math.Max(r1, r2), math.Min(r1, r2))
+}
+
+// visViva returns the speed [m/s] at radius r of orbit o.
+func visViva(gravParam, r float64, o Elliptical) float64 {
+	return math.Sqrt(gravParam * (2/r - 1/o.a()))
+}
+
+// tangentialBurn returns the RSW change of velocity that changes an along track speed v0 to v1 rotated by angle towards W.
+func tangentialBurn(v0, v1, angle float64) md3.Vec {
+	s, c := math.Sincos(angle)
+	return md3.Vec{Y: v1*c - v0, Z: v1 * s}
+}
+
+// combinedDeltaV returns the magnitude of [tangentialBurn].
+func combinedDeltaV(v0, v1, angle float64) float64 {
+	return math.Sqrt(math.Max(0, v0*v0+v1*v1-2*v0*v1*math.Cos(angle)))
+}
+
+// goldenSection returns the minimum of unimodal f in [a, b].
+func goldenSection(f func(float64) float64, a, b, tol float64) float64 {
+	const invphi = 0.6180339887498948482045868343656381177203091798057628621354486227
+	c, d := b-invphi*(b-a), a+invphi*(b-a)
+	fc, fd := f(c), f(d)
+	for b-a > tol {
+		if fc < fd {
+			b, d, fd = d, c, fc
+			c = b - invphi*(b-a)
+			fc = f(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invphi*(b-a)
+			fd = f(d)
+		}
+	}
+	return (a + b) / 2
+}
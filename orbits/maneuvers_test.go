@@ -0,0 +1,151 @@
+package orbits
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestHohmann(t *testing.T) {
+	mu := earthGravParam
+	const r1, r2 = 6678e3, 42164e3
+	leo, _ := NewCircular(r1)
+	geo, _ := NewCircular(r2)
+	m, err := Hohmann(leo, geo, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := (r1 + r2) / 2
+	want := math.Sqrt(mu/r1)*(math.Sqrt(2*r2/(r1+r2))-1) + math.Sqrt(mu/r2)*(1-math.Sqrt(2*r1/(r1+r2)))
+	if math.Abs(m.DeltaV()-want) > 1e-6 {
+		t.Errorf("hohmann delta-v want %g, got %g", want, m.DeltaV())
+	}
+	if d := m.Duration() - math.Pi*math.Sqrt(at*at*at/mu); math.Abs(d) > 1e-6 {
+		t.Errorf("hohmann duration error %g", d)
+	}
+	r, v := flyManeuver(t, m, md3.Vec{X: r1}, md3.Vec{Y: math.Sqrt(mu / r1)}, mu)
+	final, err := NewEllipticalFromState(r, v, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(final.Apoapsis()-r2) > 1 || math.Abs(final.Periapsis()-r2) > 1 {
+		t.Errorf("final orbit want circular at %g, got ra=%g rp=%g", r2, final.Apoapsis(), final.Periapsis())
+	}
+
+	// Lowering back is symmetric.
+	back, err := Hohmann(geo, leo, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(back.DeltaV()-want) > 1e-6 {
+		t.Errorf("lowering delta-v want %g, got %g", want, back.DeltaV())
+	}
+}
+
+func TestHohmannPlaneChange(t *testing.T) {
+	mu := earthGravParam
+	const r1, r2, di = 6678e3, 42164e3, 28.5 * math.Pi / 180
+	leo, _ := NewCircular(r1)
+	geo, _ := NewCircular(r2)
+	m, err := HohmannPlaneChange(leo, geo, di, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Combined maneuver is cheaper than the Hohmann plus a plane change at the apoapsis.
+	hohmann, _ := Hohmann(leo, geo, mu)
+	separate := hohmann.DeltaV() + PlaneChange(geo, 0, di, mu).DeltaV()
+	if m.DeltaV() >= separate {
+		t.Errorf("combined delta-v %g not below separate %g", m.DeltaV(), separate)
+	}
+	// Most of the plane change is done at the apoapsis.
+	if math.Abs(m.Burns[0].DeltaV.Z) > math.Abs(m.Burns[1].DeltaV.Z) {
+		t.Errorf("unexpected plane change split %v", m.Burns)
+	}
+	// Start in an orbit inclined di about the X axis and end equatorial.
+	s, c := math.Sincos(di)
+	v0 := math.Sqrt(mu / r1)
+	r, v := flyManeuver(t, m, md3.Vec{X: r1}, md3.Vec{Y: v0 * c, Z: -v0 * s}, mu)
+	h := md3.Unit(md3.Cross(r, v))
+	if math.Abs(h.Z-1) > 1e-9 {
+		t.Errorf("final orbit not equatorial, normal %v", h)
+	}
+	if math.Abs(md3.Norm(v)-math.Sqrt(mu/r2)) > 1e-6 {
+		t.Errorf("final speed want %g, got %g", math.Sqrt(mu/r2), md3.Norm(v))
+	}
+}
+
+func TestBiElliptic(t *testing.T) {
+	mu := earthGravParam
+	const r1 = 7000e3
+	from, _ := NewCircular(r1)
+	to, _ := NewCircular(20 * r1)
+	hohmann, _ := Hohmann(from, to, mu)
+	m, err := BiElliptic(from, to, 60*r1, mu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bi-elliptic is cheaper for radius ratios above 15.58 with a distant intermediate apsis.
+	if m.DeltaV() >= hohmann.DeltaV() {
+		t.Errorf("bi-elliptic delta-v %g not below hohmann %g", m.DeltaV(), hohmann.DeltaV())
+	}
+	r, v := flyManeuver(t, m, md3.Vec{X: r1}, md3.Vec{Y: math.Sqrt(mu / r1)}, mu)
+	if d := md3.Norm(r) - 20*r1; math.Abs(d) > 1 {
+		t.Errorf("final radius error %g", d)
+	}
+	if d := md3.Dot(v, md3.Unit(r)); math.Abs(d) > 1e-6 {
+		t.Errorf("final radial velocity %g", d)
+	}
+	if _, err := BiElliptic(from, to, 10*r1, mu); err == nil {
+		t.Error("expected error for intermediate radius within final orbit")
+	}
+}
+
+func TestPhasing(t *testing.T) {
+	mu := earthGravParam
+	o, _ := NewElliptical(12000e3, 7000e3)
+	rp := o.Periapsis()
+	_, vp := o.Velocity(mu, 0)
+	r0, v0 := md3.Vec{X: rp}, md3.Vec{Y: vp}
+	// Target leads by 30 degrees of true anomaly.
+	lead := o.ElapsedSincePeriapsis(mu, 30*math.Pi/180)
+	for _, revs := range []int{1, 3} {
+		m, err := Phasing(o, lead, revs, mu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, v := flyManeuver(t, m, r0, v0, mu)
+		rt, vt, err := KeplerPropagate(r0, v0, lead+m.Duration(), mu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := md3.Norm(md3.Sub(r, rt)); d > 1 {
+			t.Errorf("revs=%d: rendezvous position error %g", revs, d)
+		}
+		if d := md3.Norm(md3.Sub(v, vt)); d > 1e-3 {
+			t.Errorf("revs=%d: rendezvous velocity error %g", revs, d)
+		}
+	}
+	if _, err := Phasing(o, -o.Period(mu), 1, mu); err != nil {
+		t.Error(err)
+	}
+	if _, err := Phasing(o, 2*o.Period(mu), 1, mu); err == nil {
+		t.Error("expected error for negative phasing period")
+	}
+}
+
+// flyManeuver applies the burns of m to a body starting at inertial r, v and returns its state after the last burn.
+func flyManeuver(t *testing.T, m Maneuver, r, v md3.Vec, gm float64) (md3.Vec, md3.Vec) {
+	t.Helper()
+	var tPrev float64
+	for _, b := range m.Burns {
+		var err error
+		r, v, err = KeplerPropagate(r, v, b.T-tPrev, gm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v = md3.Add(v, b.Inertial(r, v))
+		tPrev = b.T
+	}
+	return r, v
+}
@@ -0,0 +1,95 @@
+package orbits
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// The LVLH (local vertical local horizontal) frame of a chief body is its RSW frame, see [TRSW]: x is radially
+// outward, y along track and z along the orbital angular momentum. Relative states of a deputy body are given
+// in the LVLH frame rotating with the chief.
+
+// RelativeToLVLH returns the position [m] and velocity [m/s] of a deputy at inertial state (rd, vd) relative to a
+// chief at inertial state (rc, vc) in the chief's rotating LVLH frame. Curtis, Howard's Orbital Mechanics, Algorithm 7.1.
+func RelativeToLVLH(rc, vc, rd, vd md3.Vec) (r, v md3.Vec) {
+	T := TRSW(rc, vc)
+	rrel := md3.Sub(rd, rc)
+	vrel := md3.Sub(md3.Sub(vd, vc), md3.Cross(lvlhRate(rc, vc), rrel))
+	return md3.MulMatVec(T, rrel), md3.MulMatVec(T, vrel)
+}
+
+// LVLHToInertial returns the inertial position [m] and velocity [m/s] of a deputy at position r [m] and velocity v [m/s]
+// in the rotating LVLH frame of a chief at inertial state (rc, vc). It is the inverse of [RelativeToLVLH].
+func LVLHToInertial(rc, vc, r, v md3.Vec) (rd, vd md3.Vec) {
+	T := TRSW(rc, vc)
+	rrel := md3.MulMatVecTrans(T, r)
+	vrel := md3.Add(md3.MulMatVecTrans(T, v), md3.Cross(lvlhRate(rc, vc), rrel))
+	return md3.Add(rc, rrel), md3.Add(vc, vrel)
+}
+
+// lvlhRate returns the inertial angular velocity of the LVLH frame of a chief at inertial state (rc, vc) [rad/s].
+func lvlhRate(rc, vc md3.Vec) md3.Vec {
+	return md3.Scale(1/md3.Norm2(rc), md3.Cross(rc, vc))
+}
+
+// CWTransition returns the blocks of the state transition matrix of the Clohessy-Wiltshire (Hill) equations
+//
+//	ẍ - 3n²x - 2nẏ = 0
+//	ÿ + 2nẋ = 0
+//	z̈ + n²z = 0
+//
+// over t seconds for a chief in circular orbit with mean motion n [rad/s]. The relative LVLH state after t
+// seconds is r = rr*r0 + rv*v0 and v = vr*r0 + vv*v0. The equations are linearized about the chief and
+// are accurate while the separation is small compared to the chief's orbit radius.
+// Curtis, Howard's Orbital Mechanics, Eqns (7.53).
+func CWTransition(n, t float64) (rr, rv, vr, vv md3.Mat3) {
+	nt := n * t
+	s, c := math.Sincos(nt)
+	rr = md3.NewMat3([]float64{
+		4 - 3*c, 0, 0,
+		6 * (s - nt), 1, 0,
+		0, 0, c,
+	})
+	rv = md3.NewMat3([]float64{
+		s / n, 2 * (1 - c) / n, 0,
+		2 * (c - 1) / n, (4*s - 3*nt) / n, 0,
+		0, 0, s / n,
+	})
+	vr = md3.NewMat3([]float64{
+		3 * n * s, 0, 0,
+		6 * n * (c - 1), 0, 0,
+		0, 0, -n * s,
+	})
+	vv = md3.NewMat3([]float64{
+		c, 2 * s, 0,
+		-2 * s, 4*c - 3, 0,
+		0, 0, c,
+	})
+	return rr, rv, vr, vv
+}
+
+// CWPropagate returns the LVLH position [m] and velocity [m/s] of a deputy t seconds after it was at r0 with
+// velocity v0 relative to a chief in circular orbit with mean motion n [rad/s]. See [CWTransition].
+func CWPropagate(n float64, r0, v0 md3.Vec, t float64) (r, v md3.Vec) {
+	rr, rv, vr, vv := CWTransition(n, t)
+	r = md3.Add(md3.MulMatVec(rr, r0), md3.MulMatVec(rv, v0))
+	v = md3.Add(md3.MulMatVec(vr, r0), md3.MulMatVec(vv, v0))
+	return r, v
+}
+
+// CWRendezvous returns the two impulsive burns in LVLH frame [m/s] that take a deputy at LVLH state (r0, v0)
+// to the LVLH state (rf, vf) in tof seconds relative to a chief in circular orbit with mean motion n [rad/s].
+// The second burn is applied tof seconds after the first. Set rf and vf to zero to rendezvous with the chief.
+// Burns are converted to inertial frame with the chief's state at the time of the burn, see [TRSW].
+// An error is returned if the transfer time is a singular multiple of the orbit period.
+func CWRendezvous(n float64, r0, v0, rf, vf md3.Vec, tof float64) (dv0, dvf md3.Vec, err error) {
+	rr, rv, vr, vv := CWTransition(n, tof)
+	if det := rv.Determinant(); math.Abs(det) < 1e-9/(n*n*n) {
+		return md3.Vec{}, md3.Vec{}, errors.New("CW rendezvous transfer time is singular")
+	}
+	v0plus := md3.MulMatVec(rv.Inverse(), md3.Sub(rf, md3.MulMatVec(rr, r0)))
+	vfminus := md3.Add(md3.MulMatVec(vr, r0), md3.MulMatVec(vv, v0plus))
+	return md3.Sub(v0plus, v0), md3.Sub(vf, vfminus), nil
+}
@@ -0,0 +1,94 @@
+package orbits
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestLVLHRoundTrip(t *testing.T) {
+	mu := earthGravParam
+	el := Elements{SemiMajorAxis: 7000e3, Eccentricity: 0.05, Inclination: 0.9, RAAN: 1, ArgPeriapsis: 2, TrueAnomaly: 0.3}
+	rc, vc := el.State(mu)
+	r, v := md3.Vec{X: 100, Y: -2000, Z: 30}, md3.Vec{X: 0.1, Y: 0.2, Z: -0.3}
+	rd, vd := LVLHToInertial(rc, vc, r, v)
+	gotr, gotv := RelativeToLVLH(rc, vc, rd, vd)
+	if !md3.EqualElem(gotr, r, 1e-6) || !md3.EqualElem(gotv, v, 1e-9) {
+		t.Errorf("want (%v,%v), got (%v,%v)", r, v, gotr, gotv)
+	}
+	// A deputy ahead in the same circular orbit is stationary in LVLH.
+	circ := Elements{SemiMajorAxis: 7000e3, TrueAnomaly: 0.3}
+	rc, vc = circ.State(mu)
+	circ.TrueAnomaly += 1e-4
+	rd, vd = circ.State(mu)
+	_, v = RelativeToLVLH(rc, vc, rd, vd)
+	if md3.Norm(v) > 1e-9 {
+		t.Errorf("co-orbiting deputy relative velocity %v", v)
+	}
+}
+
+// relativeStates returns the inertial states of a chief in a circular orbit of the given radius and of a deputy
+// at LVLH state (r0, v0), and the chief's mean motion.
+func relativeStates(t *testing.T, radius float64, r0, v0 md3.Vec) (rc, vc, rd, vd md3.Vec, n float64) {
+	t.Helper()
+	orbit, err := NewCircular(radius)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, vt := orbit.Velocity(earthGravParam, 0)
+	rc, vc = md3.Vec{X: radius}, md3.Vec{Y: vt * math.Cos(0.5), Z: vt * math.Sin(0.5)}
+	rd, vd = LVLHToInertial(rc, vc, r0, v0)
+	return rc, vc, rd, vd, orbit.MeanMotion(earthGravParam)
+}
+
+// kepler propagates the inertial state (r, v) by dt seconds around the Earth.
+func kepler(t *testing.T, r, v md3.Vec, dt float64) (md3.Vec, md3.Vec) {
+	t.Helper()
+	r, v, err := KeplerPropagate(r, v, dt, earthGravParam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, v
+}
+
+func TestCWPropagate(t *testing.T) {
+	r0, v0 := md3.Vec{X: 200, Y: -1000, Z: 100}, md3.Vec{X: 0.1, Y: -0.4, Z: 0.2}
+	rc0, vc0, rd0, vd0, n := relativeStates(t, 7000e3, r0, v0)
+	period := 2 * math.Pi / n
+	for _, tf := range []float64{period / 4, period} {
+		rc, vc := kepler(t, rc0, vc0, tf)
+		rd, vd := kepler(t, rd0, vd0, tf)
+		gotr, gotv := RelativeToLVLH(rc, vc, rd, vd)
+		wantr, wantv := CWPropagate(n, r0, v0, tf)
+		// Linearization error grows with separation squared over orbit radius.
+		if d := md3.Norm(md3.Sub(gotr, wantr)); d > 5 {
+			t.Errorf("t=%.0f: CW position error %gm", tf, d)
+		}
+		if d := md3.Norm(md3.Sub(gotv, wantv)); d > 5e-3 {
+			t.Errorf("t=%.0f: CW velocity error %gm/s", tf, d)
+		}
+	}
+}
+
+func TestCWRendezvous(t *testing.T) {
+	r0, v0 := md3.Vec{X: 500, Y: -3000, Z: 200}, md3.Vec{X: 0, Y: 0.5, Z: 0}
+	rc, vc, rd, vd, n := relativeStates(t, 7000e3, r0, v0)
+	tof := 0.4 * 2 * math.Pi / n
+	dv0, dvf, err := CWRendezvous(n, r0, v0, md3.Vec{}, md3.Vec{}, tof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Burns are applied to the deputy in the chief's LVLH frame at the time of the burn.
+	vd = md3.Add(vd, md3.MulMatVecTrans(TRSW(rc, vc), dv0))
+	rc, vc = kepler(t, rc, vc, tof)
+	rd, vd = kepler(t, rd, vd, tof)
+	vd = md3.Add(vd, md3.MulMatVecTrans(TRSW(rc, vc), dvf))
+	r, v := RelativeToLVLH(rc, vc, rd, vd)
+	if md3.Norm(r) > 10 || md3.Norm(v) > 0.02 {
+		t.Errorf("rendezvous miss: position %v, velocity %v", r, v)
+	}
+	if _, _, err := CWRendezvous(n, r0, v0, md3.Vec{}, md3.Vec{}, 2*math.Pi/n); err == nil {
+		t.Error("expected error for transfer over a full period")
+	}
+}
@@ -0,0 +1,234 @@
+package gnco
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Ephemeris returns the inertial position [m] of a body at epoch time t [s]. It may be backed by
+// an analytic propagator or by a [PhysicsPointIntegrator] through [PhysicsPointIntegrator.Ephemeris].
+type Ephemeris func(t float64) (SBI md3.Vec)
+
+// Ephemeris returns an [Ephemeris] that propagates phys to the requested epoch time with steps no
+// larger than maxStep [s]. The integrator's state is modified on every call.
+func (phys *PhysicsPointIntegrator) Ephemeris(maxStep float64) Ephemeris {
+	return func(t float64) md3.Vec {
+		SBI, _ := phys.Propagate(t, maxStep)
+		return SBI
+	}
+}
+
+// PassSample is the position of a body in the sky of a ground station.
+type PassSample struct {
+	T         float64 // Epoch time [s].
+	Azimuth   float64 // Clockwise from North [rad].
+	Elevation float64 // Above the horizon [rad].
+	Range     float64 // Slant range [m].
+}
+
+// Pass is an interval during which a body is visible above a ground station's elevation mask.
+type Pass struct {
+	// AOS is the acquisition of signal, the first sample at which the body is visible.
+	// It is the start of the search interval if the pass was in progress.
+	AOS PassSample
+	// LOS is the loss of signal, the last sample at which the body is visible.
+	// It is the end of the search interval if the pass had not ended.
+	LOS PassSample
+	// Max is the sample of maximum elevation.
+	Max PassSample
+	// Samples is the azimuth, elevation and range time series from AOS to LOS.
+	Samples []PassSample
+}
+
+// Duration returns the duration of the pass [s].
+func (p Pass) Duration() float64 { return p.LOS.T - p.AOS.T }
+
+// PassPredictor finds the passes of a body over a ground station.
+type PassPredictor struct {
+	Station GeocentricCoords
+	// MinElevation is the elevation mask [rad] below which the body is not visible.
+	MinElevation float64
+	// Mask optionally overrides MinElevation with an elevation mask [rad] that depends on
+	// azimuth [rad] to model terrain or buildings around the station.
+	Mask func(azimuth float64) float64
+	// Step is the search step [s]. Passes shorter than Step may be missed.
+	Step float64
+	// Tolerance is the accuracy of AOS, LOS and maximum elevation times [s].
+	Tolerance float64
+	// SampleInterval is the time between samples of the pass time series [s]. Zero disables the series.
+	SampleInterval float64
+}
+
+// NewPassPredictor returns a pass predictor for station with an elevation mask minElevation [rad]
+// searching in steps of 10 seconds with 1 millisecond tolerance and sampling the series every 10 seconds.
+func NewPassPredictor(station GeocentricCoords, minElevation float64) *PassPredictor {
+	return &PassPredictor{
+		Station:        station,
+		MinElevation:   minElevation,
+		Step:           10,
+		Tolerance:      1e-3,
+		SampleInterval: 10,
+	}
+}
+
+// Sample returns the look angles of the body given by eph at epoch time t.
+func (pp *PassPredictor) Sample(eph Ephemeris, t float64) PassSample {
+	az, el, rng := pp.Station.LookAnglesInertial(t, eph(t))
+	return PassSample{T: t, Azimuth: az, Elevation: el, Range: rng}
+}
+
+// Visible reports whether a body at sample s is above the elevation mask.
+func (pp *PassPredictor) Visible(s PassSample) bool {
+	return s.Elevation >= pp.mask(s.Azimuth)
+}
+
+func (pp *PassPredictor) mask(azimuth float64) float64 {
+	if pp.Mask != nil {
+		return pp.Mask(azimuth)
+	}
+	return pp.MinElevation
+}
+
+// Passes returns the passes of the body given by eph over the station between epoch times t0 and t1 [s]
+// in chronological order. eph is evaluated at increasing times during the search except when refining
+// AOS, LOS and maximum elevation and sampling the time series after AOS, which never go back more
+// than two search steps.
+func (pp *PassPredictor) Passes(eph Ephemeris, t0, t1 float64) []Pass {
+	if !(pp.Step > 0) || !(pp.Tolerance > 0) {
+		panic("pass predictor step and tolerance must be positive")
+	}
+	var passes []Pass
+	var tr passTracker
+	prev := pp.Sample(eph, t0)
+	if pp.Visible(prev) {
+		tr.begin(prev)
+	}
+	for t := t0; t < t1; {
+		tn := math.Min(t+pp.Step, t1)
+		if tr.active {
+			pp.sampleSeries(eph, &tr, tn)
+		}
+		s := pp.Sample(eph, tn)
+		visible := pp.Visible(s)
+		switch {
+		case !tr.active && visible:
+			tr.begin(pp.bisect(eph, prev, s, true))
+			pp.sampleSeries(eph, &tr, tn)
+		case tr.active && !visible:
+			passes = append(passes, pp.finish(eph, &tr, pp.bisect(eph, prev, s, false)))
+		}
+		if tr.active {
+			pp.updateMax(eph, &tr, s)
+		}
+		prev, t = s, tn
+	}
+	if tr.active {
+		passes = append(passes, pp.finish(eph, &tr, prev))
+	}
+	return passes
+}
+
+// passTracker accumulates the pass in progress during the forward search of [PassPredictor.Passes].
+type passTracker struct {
+	active bool
+	pass   Pass
+	// next is the epoch time of the next sample of the time series [s].
+	next float64
+	// refined is set once the maximum elevation has been refined about the best search sample.
+	refined bool
+}
+
+func (tr *passTracker) begin(aos PassSample) {
+	*tr = passTracker{active: true, pass: Pass{AOS: aos, Max: aos}, next: aos.T}
+}
+
+// sampleSeries appends the time series samples of the pass in progress before epoch time t.
+func (pp *PassPredictor) sampleSeries(eph Ephemeris, tr *passTracker, t float64) {
+	if !(pp.SampleInterval > 0) {
+		return
+	}
+	for ; tr.next < t; tr.next += pp.SampleInterval {
+		s := tr.pass.AOS
+		if tr.next != s.T {
+			s = pp.Sample(eph, tr.next)
+		}
+		tr.pass.Samples = append(tr.pass.Samples, s)
+	}
+}
+
+// updateMax keeps the best search sample of the pass in progress and refines the maximum
+// elevation about it once the elevation at search sample s starts decreasing.
+func (pp *PassPredictor) updateMax(eph Ephemeris, tr *passTracker, s PassSample) {
+	if s.Elevation > tr.pass.Max.Elevation {
+		tr.pass.Max = s
+		tr.refined = false
+	} else if !tr.refined {
+		pp.refineMax(eph, &tr.pass, s.T)
+		tr.refined = true
+	}
+}
+
+// finish ends the pass in progress at los and returns it.
+func (pp *PassPredictor) finish(eph Ephemeris, tr *passTracker, los PassSample) Pass {
+	p := &tr.pass
+	p.LOS = los
+	if !tr.refined {
+		pp.refineMax(eph, p, los.T)
+	}
+	if los.Elevation > p.Max.Elevation {
+		p.Max = los
+	}
+	if pp.SampleInterval > 0 {
+		// Series samples taken after LOS within the last search step are discarded.
+		n := len(p.Samples)
+		for n > 0 && p.Samples[n-1].T >= los.T {
+			n--
+		}
+		p.Samples = append(p.Samples[:n], los)
+	}
+	tr.active = false
+	return *p
+}
+
+// bisect returns the first visible sample between a and b when rising is true or the last visible
+// sample when rising is false. Visibility changes between a and b.
+func (pp *PassPredictor) bisect(eph Ephemeris, a, b PassSample, rising bool) PassSample {
+	for b.T-a.T > pp.Tolerance {
+		mid := pp.Sample(eph, (a.T+b.T)/2)
+		if pp.Visible(mid) == rising {
+			b = mid
+		} else {
+			a = mid
+		}
+	}
+	if rising {
+		return b
+	}
+	return a
+}
+
+// refineMax refines the maximum elevation of p with a golden section search between
+// a search step before its best sample and epoch time tEnd.
+func (pp *PassPredictor) refineMax(eph Ephemeris, p *Pass, tEnd float64) {
+	const invphi = 0.6180339887498948482045868343656381177203091798057628621354486227
+	a, b := math.Max(p.Max.T-pp.Step, p.AOS.T), tEnd
+	c, d := b-invphi*(b-a), a+invphi*(b-a)
+	sc, sd := pp.Sample(eph, c), pp.Sample(eph, d)
+	for b-a > pp.Tolerance {
+		if sc.Elevation > sd.Elevation {
+			b, d, sd = d, c, sc
+			c = b - invphi*(b-a)
+			sc = pp.Sample(eph, c)
+		} else {
+			a, c, sc = c, d, sd
+			d = a + invphi*(b-a)
+			sd = pp.Sample(eph, d)
+		}
+	}
+	for _, s := range [...]PassSample{sc, sd} {
+		if s.Elevation > p.Max.Elevation {
+			p.Max = s
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestPasses(t *testing.T) {
+	// Equatorial circular orbit passing over an equatorial station. The central angle between station
+	// and satellite at elevation el is acos(R*cos(el)/r) - el and it closes at the relative angular rate.
+	earth := NewEarth()
+	station := earth.GeocentricFromDegrees(0, 0, 0)
+	R, r := station.Radius(), earth.SemiMajorAxis+1000e3
+	n := math.Sqrt(earth.G() / (r * r * r))
+	relRate := n - earth.Rotation
+	SSI, _ := station.InertialCoords(0)
+	phase0 := math.Atan2(SSI.Y, SSI.X) - 60*deg // Satellite starts 60 degrees behind station.
+	analytic := Ephemeris(func(t float64) md3.Vec {
+		s, c := math.Sincos(phase0 + n*t)
+		return md3.Vec{X: r * c, Y: r * s}
+	})
+	coords := station
+	s, c := math.Sincos(phase0)
+	phys := NewPhysicsPointIntegrator(&coords, 0, md3.Vec{X: r * c, Y: r * s}, md3.Vec{X: -n * r * s, Y: n * r * c})
+	synodic := 2 * math.Pi / relRate
+	for _, test := range []struct {
+		name string
+		eph  Ephemeris
+	}{
+		{"analytic", analytic},
+		{"integrator", phys.Ephemeris(30)},
+	} {
+		for _, mask := range []float64{0, 10 * deg} {
+			pp := NewPassPredictor(station, mask)
+			// The search must not go back further than two search steps.
+			var latest float64
+			eph := func(te float64) md3.Vec {
+				if te < latest-2*pp.Step {
+					t.Errorf("%s: ephemeris evaluated at %g after %g", test.name, te, latest)
+				}
+				latest = math.Max(latest, te)
+				return test.eph(te)
+			}
+			passes := pp.Passes(eph, 0, 1.5*synodic)
+			if len(passes) != 2 {
+				t.Fatalf("%s: want 2 passes, got %d", test.name, len(passes))
+			}
+			theta := math.Acos(R*math.Cos(mask)/r) - mask
+			wantDuration := 2 * theta / relRate
+			wantMax := 60 * deg / relRate
+			for i, p := range passes {
+				tMax := wantMax + float64(i)*synodic
+				if math.Abs(p.Duration()-wantDuration) > 0.01 {
+					t.Errorf("%s mask=%g: pass %d duration want %g, got %g", test.name, mask, i, wantDuration, p.Duration())
+				}
+				if math.Abs(p.Max.T-tMax) > 0.01 || math.Abs(p.Max.Elevation-90*deg) > 1e-3 {
+					t.Errorf("%s mask=%g: pass %d max elevation %g at %g, want 90 at %g", test.name, mask, i, p.Max.Elevation/deg, p.Max.T, tMax)
+				}
+				if math.Abs(p.AOS.Elevation-mask) > 1e-4 || math.Abs(p.LOS.Elevation-mask) > 1e-4 {
+					t.Errorf("%s mask=%g: AOS/LOS elevation %g %g", test.name, mask, p.AOS.Elevation/deg, p.LOS.Elevation/deg)
+				}
+				// Prograde satellite rises in the West and sets in the East.
+				if math.Abs(p.AOS.Azimuth-270*deg) > 1e-3 || math.Abs(p.LOS.Azimuth-90*deg) > 1e-3 {
+					t.Errorf("%s mask=%g: AOS/LOS azimuth %g %g", test.name, mask, p.AOS.Azimuth/deg, p.LOS.Azimuth/deg)
+				}
+				if len(p.Samples) < int(p.Duration()/pp.SampleInterval) || p.Samples[0].T != p.AOS.T {
+					t.Errorf("%s: pass %d has %d samples", test.name, i, len(p.Samples))
+				}
+				for _, s := range p.Samples {
+					if s.Elevation < mask-1e-4 {
+						t.Errorf("%s: sample below mask at %g", test.name, s.T)
+					}
+				}
+			}
+		}
+	}
+
+	// Azimuth dependent mask blocking the Eastern sky below 30 degrees shortens the pass after culmination.
+	pp := NewPassPredictor(station, 0)
+	pp.Mask = func(az float64) float64 {
+		if az < 180*deg {
+			return 30 * deg
+		}
+		return 0
+	}
+	passes := pp.Passes(analytic, 0, synodic)
+	if len(passes) != 1 {
+		t.Fatalf("want 1 pass, got %d", len(passes))
+	}
+	theta0, theta30 := math.Acos(R/r), math.Acos(R*math.Cos(30*deg)/r)-30*deg
+	if want := (theta0 + theta30) / relRate; math.Abs(passes[0].Duration()-want) > 0.01 {
+		t.Errorf("masked pass duration want %g, got %g", want, passes[0].Duration())
+	}
+}
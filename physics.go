@@ -1,60 +1,348 @@
 package gnco
 
 import (
+	"math"
+
 	"github.com/soypat/geometry/md3"
 	"github.com/soypat/gnco/internal/ode"
 )
 
 type PhysicsPointIntegrator struct {
-	integrator        ode.RKN1210
+	integrator        stepper
+	method            IntegrationMethod
 	coord             Coordinates
 	lastInternalAccel md3.Vec
+	forces            []namedForce
+	// State at start of step and acceleration at first stage used to estimate stage velocities.
+	stepT0         float64
+	stepV0, stepA0 md3.Vec
+	// stm is true if the variational equations are integrated alongside the state.
+	stm bool
+	// burns are the scheduled burns and activeBurns the indices of finite burns acting during the current step.
+	burns       []Burn
+	activeBurns []int
+}
+
+// stepper is implemented by the second order integrators in package ode.
+type stepper interface {
+	Step(h float64) (float64, error)
+	State() (t float64, y, dy md3.Vec)
+	SetState(t float64, y, dy md3.Vec)
+	Variational() (Y, DY [2]md3.Mat3)
+	SetVariational(Y, DY [2]md3.Mat3)
 }
 
+// IntegrationMethod selects the numerical scheme used by a [PhysicsPointIntegrator].
+type IntegrationMethod uint8
+
+const (
+	// MethodRKN1210 is the default Runge-Kutta-Nyström 12(10) scheme. It is very accurate for
+	// large steps but force models receive stage velocities estimated to first order within a step.
+	// It is best suited for forces that do not depend on velocity such as gravity.
+	MethodRKN1210 IntegrationMethod = iota
+	// MethodGRKN54 is a general Runge-Kutta-Nyström 5(4) scheme where force models receive exact
+	// stage velocities. Use it when forces depend on velocity, such as drag or thrust along velocity.
+	// It requires smaller steps than MethodRKN1210 for the same accuracy.
+	MethodGRKN54
+)
+
+type namedForce struct {
+	name  string
+	model ForceModel
+}
+
+// NewPhysicsPointIntegrator returns a new integrator for a point body at inertial position SBI0 and
+// velocity VBI0 at epoch time t0. The integrator is created with the central body [Gravity] force
+// model registered under the name "gravity".
 func NewPhysicsPointIntegrator(coord Coordinates, t0 float64, SBI0, VBI0 md3.Vec) *PhysicsPointIntegrator {
 	p := &PhysicsPointIntegrator{
 		coord: coord,
-		integrator: *ode.NewRKN1210(ode.DefaultRelaxFactor, ode.DefaultPreconditioner, ode.Parameters{
-			AbsTolerance: 0,
-			MinStep:      0,
-			MaxStep:      0,
-		}),
-	}
-	p.integrator.Init(ode.IVP2{
-		T0:   t0,
-		Y0:   SBI0,
-		DY0:  VBI0,
-		Func: p.accel,
-	})
+	}
+	p.initMethod(MethodRKN1210, t0, SBI0, VBI0)
+	p.AddForceModel("gravity", Gravity{})
 	return p
 }
 
+// SetMethod changes the integration method keeping the current state of the body and state transition matrix.
+func (phys *PhysicsPointIntegrator) SetMethod(method IntegrationMethod) {
+	t, SBI, VBI := phys.integrator.State()
+	Y, DY := phys.integrator.Variational()
+	phys.initMethod(method, t, SBI, VBI)
+	phys.integrator.SetVariational(Y, DY)
+}
+
+// Method returns the integration method in use.
+func (phys *PhysicsPointIntegrator) Method() IntegrationMethod { return phys.method }
+
+func (phys *PhysicsPointIntegrator) initMethod(method IntegrationMethod, t0 float64, SBI0, VBI0 md3.Vec) {
+	params := ode.Parameters{
+		AbsTolerance: 0,
+		MinStep:      0,
+		MaxStep:      0,
+	}
+	switch method {
+	case MethodRKN1210:
+		rk := ode.NewRKN1210(ode.DefaultRelaxFactor, ode.DefaultPreconditioner, params)
+		ivp := ode.IVP2{
+			T0:   t0,
+			Y0:   SBI0,
+			DY0:  VBI0,
+			Func: phys.accel,
+		}
+		if phys.stm {
+			ivp.Jac = phys.jac
+		}
+		rk.Init(ivp)
+		phys.integrator = rk
+	case MethodGRKN54:
+		rk := ode.NewGRKN54(ode.DefaultRelaxFactor, params)
+		ivp := ode.IVP2General{
+			T0:   t0,
+			Y0:   SBI0,
+			DY0:  VBI0,
+			Func: phys.accelGeneral,
+		}
+		if phys.stm {
+			ivp.Jac = phys.jacGeneral
+		}
+		rk.Init(ivp)
+		phys.integrator = rk
+	default:
+		panic("unknown integration method")
+	}
+	phys.method = method
+}
+
 // Step steps the physics engine with the external acceleration in geographical frame which is obtained by TVG*ABV.
 // Gravity should not be included in the external acceleration as it is obtained from the coordinate system [Coordinates] AGravG method.
+// The external acceleration is held constant during the step, accelerations that depend on the
+// state of the body should be added as a [ForceModel] with [PhysicsPointIntegrator.AddForceModel].
+// Forward steps are split at the start and end of burns scheduled with [PhysicsPointIntegrator.ScheduleBurn].
 func (phys *PhysicsPointIntegrator) Step(dt float64, externalAccelGeographicFrameNoGravity md3.Vec) (t float64, SBI, VBI md3.Vec) {
 	phys.lastInternalAccel = externalAccelGeographicFrameNoGravity
-	phys.integrator.Step(dt)
+	t, _, _ = phys.integrator.State()
+	tEnd := t + dt
+	for {
+		tNext, burnEvent := tEnd, false
+		if dt > 0 {
+			tNext, burnEvent = phys.nextBurnEvent(t, tEnd)
+		}
+		phys.activateBurns(t, tNext-t)
+		phys.stepT0, _, phys.stepV0 = phys.integrator.State()
+		phys.integrator.Step(tNext - t)
+		t, SBI, VBI = phys.integrator.State()
+		if burnEvent {
+			// Avoid accumulating round-off at burn times.
+			t = tNext
+			phys.integrator.SetState(t, SBI, VBI)
+			phys.applyImpulses(t)
+		}
+		if tNext == tEnd {
+			break
+		}
+	}
+	phys.activeBurns = phys.activeBurns[:0]
 	return phys.integrator.State()
 }
 
+// State returns the current epoch time, inertial position and inertial velocity of the body.
+func (phys *PhysicsPointIntegrator) State() (t float64, SBI, VBI md3.Vec) {
+	return phys.integrator.State()
+}
+
+// SetState sets the epoch time, inertial position and inertial velocity of the body.
+// The state transition matrix is reset to identity.
+func (phys *PhysicsPointIntegrator) SetState(t float64, SBI, VBI md3.Vec) {
+	phys.integrator.SetState(t, SBI, VBI)
+	phys.integrator.SetVariational(ode.IdentityVariational())
+}
+
+// ResetSTM sets the state transition matrix to identity at the current state and enables its propagation.
+// The state transition matrix is not propagated until the first call to ResetSTM since it
+// requires evaluating the gradient of every force model at every integrator stage.
+func (phys *PhysicsPointIntegrator) ResetSTM() {
+	if !phys.stm {
+		phys.stm = true
+		t, SBI, VBI := phys.integrator.State()
+		phys.initMethod(phys.method, t, SBI, VBI)
+	}
+	phys.integrator.SetVariational(ode.IdentityVariational())
+}
+
+// STM returns the 6x6 state transition matrix of the trajectory since the last call to
+// [PhysicsPointIntegrator.ResetSTM] or [PhysicsPointIntegrator.SetState]. It holds the partial derivatives of
+// the current inertial position and velocity with respect to those at the start of the trajectory:
+//
+//	STM = ∂(SBI, VBI)/∂(SBI0, VBI0)
+//
+// It is obtained by integrating the variational equations alongside the state with the same scheme.
+// Force models implementing [ForceGradient] such as [Gravity] provide their gradient and other
+// force models are differentiated numerically. The external acceleration passed to Step is treated
+// as constant. With [MethodRKN1210] the velocity dependence of force models is neglected.
+// STM panics if ResetSTM was never called.
+func (phys *PhysicsPointIntegrator) STM() (stm [6][6]float64) {
+	if !phys.stm {
+		panic("STM propagation not enabled, call ResetSTM")
+	}
+	Y, DY := phys.integrator.Variational()
+	blocks := [2][2]md3.Mat3{{Y[0], Y[1]}, {DY[0], DY[1]}}
+	for bi := range blocks {
+		for bj := range blocks[bi] {
+			a := blocks[bi][bj].Array()
+			for i := 0; i < 3; i++ {
+				copy(stm[3*bi+i][3*bj:3*bj+3], a[3*i:3*i+3])
+			}
+		}
+	}
+	return stm
+}
+
+// checkpoint is the state of the integrator including the variational equations.
+type checkpoint struct {
+	t        float64
+	SBI, VBI md3.Vec
+	Y, DY    [2]md3.Mat3
+}
+
+// checkpoint returns the current state of the integrator so it can be rewound with [PhysicsPointIntegrator.rewind].
+func (phys *PhysicsPointIntegrator) checkpoint() (c checkpoint) {
+	c.t, c.SBI, c.VBI = phys.integrator.State()
+	c.Y, c.DY = phys.integrator.Variational()
+	return c
+}
+
+// rewind sets the integrator's state and variational equations to those of checkpoint c.
+func (phys *PhysicsPointIntegrator) rewind(c checkpoint) {
+	phys.integrator.SetState(c.t, c.SBI, c.VBI)
+	phys.integrator.SetVariational(c.Y, c.DY)
+}
+
+// Propagate steps the integrator with no external acceleration until epoch time t is reached using
+// steps no larger than maxStep. t may be before the current epoch time to propagate backwards.
+func (phys *PhysicsPointIntegrator) Propagate(t, maxStep float64) (SBI, VBI md3.Vec) {
+	if !(maxStep > 0) {
+		panic("Propagate requires positive maxStep")
+	}
+	tc, SBI, VBI := phys.integrator.State()
+	for tc != t {
+		h := t - tc
+		if math.Abs(h) > maxStep {
+			h = math.Copysign(maxStep, h)
+		}
+		_, SBI, VBI = phys.Step(h, md3.Vec{})
+		tc += h
+		if math.Abs(t-tc) < 1e-9*maxStep {
+			tc = t
+		}
+	}
+	phys.integrator.SetState(t, SBI, VBI)
+	return SBI, VBI
+}
+
+// AddForceModel adds model to the force sum evaluated at every integrator stage. The name
+// identifies the model's contribution in [PhysicsPointIntegrator.Forces] and must be unique.
+func (phys *PhysicsPointIntegrator) AddForceModel(name string, model ForceModel) {
+	if phys.forceIndex(name) >= 0 {
+		panic("force model " + name + " already registered")
+	} else if model == nil {
+		panic("nil force model")
+	}
+	phys.forces = append(phys.forces, namedForce{name: name, model: model})
+}
+
+// RemoveForceModel removes the force model registered under name and returns it.
+// It returns nil if no model was registered under name.
+func (phys *PhysicsPointIntegrator) RemoveForceModel(name string) ForceModel {
+	idx := phys.forceIndex(name)
+	if idx < 0 {
+		return nil
+	}
+	model := phys.forces[idx].model
+	phys.forces = append(phys.forces[:idx], phys.forces[idx+1:]...)
+	return model
+}
+
+func (phys *PhysicsPointIntegrator) forceIndex(name string) int {
+	for i := range phys.forces {
+		if phys.forces[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Forces evaluates every registered force model at the current state of the body and appends their
+// contributions to dst in order of registration. Finite burns in progress at the current epoch time are
+// appended last as a single contribution named "burn". The external acceleration passed to Step is not included.
+func (phys *PhysicsPointIntegrator) Forces(dst []ForceContribution) []ForceContribution {
+	t, SBI, VBI := phys.integrator.State()
+	state := phys.forceState(t, SBI, VBI)
+	for _, f := range phys.forces {
+		dst = append(dst, ForceContribution{Name: f.name, AccelInertial: f.model.Accel(&state)})
+	}
+	if ABII, ok := phys.burnAccelAt(&state); ok {
+		dst = append(dst, ForceContribution{Name: "burn", AccelInertial: ABII})
+	}
+	return dst
+}
+
+// forceState sets the integrator's coordinates from the inertial position and returns the state force models are evaluated at.
+func (phys *PhysicsPointIntegrator) forceState(t float64, SBII, VBII md3.Vec) ForceState {
+	return newForceState(phys.coord, t, SBII, VBII)
+}
+
 func (phys *PhysicsPointIntegrator) accel(yppDst []md3.Vec, tv []float64, yv []md3.Vec) {
-	coord := phys.coord
-	w := coord.World()
-	accelInternalG := phys.lastInternalAccel
 	for i := range yppDst {
-		t, SBII := tv[i], yv[i]
-		TEI := w.TEI(t)
-		SBIE := md3.MulMatVec(TEI, SBII)
-		coord.SetFromEarthFixedCoords(SBIE, t)
-		// Calculate TM geographic wrt earth coordinates.
-		TGE := coord.TGE()
-		// Calculate TM of geographic wrt inertial coordinates.
-		TGI := md3.MulMat3(TGE, TEI)
-
-		accelGravity := coord.AGravG()
-		// TM to Geographic coordinates. ABIIn = [0 0 -Az]
-		ABII := md3.Add(accelInternalG, accelGravity)
-		ABII = md3.MulMatVecTrans(TGI, ABII)
-		yppDst[i] = ABII
+		t := tv[i]
+		// Stage velocity estimated to first order from acceleration at start of step.
+		VBII := md3.Add(phys.stepV0, md3.Scale(t-phys.stepT0, phys.stepA0))
+		yppDst[i] = phys.stageAccel(t, yv[i], VBII)
+		if t == phys.stepT0 {
+			phys.stepA0 = yppDst[i]
+		}
+	}
+}
+
+func (phys *PhysicsPointIntegrator) accelGeneral(yppDst []md3.Vec, tv []float64, yv, dyv []md3.Vec) {
+	for i := range yppDst {
+		yppDst[i] = phys.stageAccel(tv[i], yv[i], dyv[i])
+	}
+}
+
+// jac returns the gradient of the acceleration with respect to position for the variational equations of MethodRKN1210.
+func (phys *PhysicsPointIntegrator) jac(t float64, SBII md3.Vec) md3.Mat3 {
+	VBII := md3.Add(phys.stepV0, md3.Scale(t-phys.stepT0, phys.stepA0))
+	dadr, _ := phys.gradient(t, SBII, VBII, false)
+	return dadr
+}
+
+// jacGeneral returns the gradient of the acceleration with respect to position and velocity for the variational equations of MethodGRKN54.
+func (phys *PhysicsPointIntegrator) jacGeneral(t float64, SBII, VBII md3.Vec) (dadr, dadv md3.Mat3) {
+	return phys.gradient(t, SBII, VBII, true)
+}
+
+// gradient returns the sum of the gradients of all force models.
+func (phys *PhysicsPointIntegrator) gradient(t float64, SBII, VBII md3.Vec, withVelocity bool) (dadr, dadv md3.Mat3) {
+	state := phys.forceState(t, SBII, VBII)
+	for _, f := range phys.forces {
+		var gr, gv md3.Mat3
+		if g, ok := f.model.(ForceGradient); ok {
+			gr, gv = g.Gradient(&state)
+		} else {
+			gr, gv = numericGradient(&state, f.model, withVelocity)
+		}
+		dadr = md3.AddMat3(dadr, gr)
+		dadv = md3.AddMat3(dadv, gv)
+	}
+	return dadr, dadv
+}
+
+// stageAccel returns the sum of the external acceleration and all force models in inertial frame.
+func (phys *PhysicsPointIntegrator) stageAccel(t float64, SBII, VBII md3.Vec) (ABII md3.Vec) {
+	state := phys.forceState(t, SBII, VBII)
+	ABII = md3.Add(md3.MulMatVecTrans(state.TGI, phys.lastInternalAccel), phys.burnAccel(&state))
+	for _, f := range phys.forces {
+		ABII = md3.Add(ABII, f.model.Accel(&state))
 	}
+	return ABII
 }
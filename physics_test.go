@@ -0,0 +1,220 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md3"
+)
+
+func TestCircularOrbitClosure(t *testing.T) {
+	earth := NewEarth()
+	const r = 7000e3
+	v := math.Sqrt(earth.G() / r)
+	period := 2 * math.Pi * r / v
+	for _, dt := range []float64{10, 60, 300} {
+		coords := earth.GeocentricFromDegrees(0, 0, 0)
+		phys := NewPhysicsPointIntegrator(&coords, 0, md3.Vec{X: r}, md3.Vec{Y: v})
+		n := int(period / dt)
+		for i := 0; i < n; i++ {
+			phys.Step(dt, md3.Vec{})
+		}
+		_, SBI, VBI := phys.Step(period-float64(n)*dt, md3.Vec{})
+		if d := md3.Norm(md3.Sub(SBI, md3.Vec{X: r})); d > 1e-3 {
+			t.Errorf("dt=%g: orbit did not close, position error %gm", dt, d)
+		}
+		if d := md3.Norm(md3.Sub(VBI, md3.Vec{Y: v})); d > 1e-6 {
+			t.Errorf("dt=%g: orbit did not close, velocity error %gm/s", dt, d)
+		}
+	}
+}
+
+func TestForceModels(t *testing.T) {
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	SBI0 := md3.Vec{X: 7000e3}
+	VBI0 := md3.Vec{Y: 7500}
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	thrust := md3.Vec{Z: 2}
+	phys.AddForceModel("thrust", ForceFunc(func(s *ForceState) md3.Vec { return thrust }))
+
+	forces := phys.Forces(nil)
+	if len(forces) != 2 || forces[0].Name != "gravity" || forces[1].Name != "thrust" {
+		t.Fatalf("unexpected contributions %+v", forces)
+	}
+	wantGravity := md3.Vec{X: -earth.G() / (7000e3 * 7000e3)}
+	if !md3.EqualElem(forces[0].AccelInertial, wantGravity, 1e-9) {
+		t.Errorf("gravity contribution: want %v, got %v", wantGravity, forces[0].AccelInertial)
+	}
+	if forces[1].AccelInertial != thrust {
+		t.Errorf("thrust contribution: want %v, got %v", thrust, forces[1].AccelInertial)
+	}
+
+	// Without gravity the body follows uniformly accelerated motion.
+	if phys.RemoveForceModel("gravity") == nil {
+		t.Fatal("gravity model not found")
+	}
+	if phys.RemoveForceModel("gravity") != nil {
+		t.Fatal("gravity model removed twice")
+	}
+	const dt = 10.
+	_, SBI, VBI := phys.Step(dt, md3.Vec{})
+	wantSBI := md3.Add(md3.Add(SBI0, md3.Scale(dt, VBI0)), md3.Scale(dt*dt/2, thrust))
+	wantVBI := md3.Add(VBI0, md3.Scale(dt, thrust))
+	if !md3.EqualElem(SBI, wantSBI, 1e-6) || !md3.EqualElem(VBI, wantVBI, 1e-9) {
+		t.Errorf("want (%v,%v), got (%v,%v)", wantSBI, wantVBI, SBI, VBI)
+	}
+}
+
+func TestVelocityDependentForce(t *testing.T) {
+	// Linear drag a=-k*v without gravity has exact solution v=v0*exp(-k*t).
+	const k, dt, tEnd = 0.5, 0.5, 10.
+	earth := NewEarth()
+	SBI0, VBI0 := md3.Vec{X: 7000e3}, md3.Vec{Y: 100, Z: 50}
+	wantVBI := md3.Scale(math.Exp(-k*tEnd), VBI0)
+	wantSBI := md3.Add(SBI0, md3.Scale((1-math.Exp(-k*tEnd))/k, VBI0))
+	velocityError := func(method IntegrationMethod) float64 {
+		coords := earth.GeocentricFromDegrees(0, 0, 0)
+		phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+		phys.SetMethod(method)
+		if phys.Method() != method {
+			t.Fatal("method not set")
+		}
+		phys.RemoveForceModel("gravity")
+		phys.AddForceModel("drag", ForceFunc(func(s *ForceState) md3.Vec { return md3.Scale(-k, s.VBI) }))
+		var SBI, VBI md3.Vec
+		for i := 0; i < int(tEnd/dt); i++ {
+			_, SBI, VBI = phys.Step(dt, md3.Vec{})
+		}
+		if method == MethodGRKN54 && !md3.EqualElem(SBI, wantSBI, 1e-5) {
+			t.Errorf("position: want %v, got %v", wantSBI, SBI)
+		}
+		return md3.Norm(md3.Sub(VBI, wantVBI))
+	}
+	errEstimated := velocityError(MethodRKN1210)
+	errExact := velocityError(MethodGRKN54)
+	if errExact > 1e-5 {
+		t.Errorf("general RKN velocity error too large: %g", errExact)
+	}
+	if errExact > errEstimated {
+		t.Errorf("general RKN should outperform estimated stage velocities: %g > %g", errExact, errEstimated)
+	}
+}
+
+func TestPropagateBackwards(t *testing.T) {
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0).Geodesic()
+	SBI0, VBI0 := md3.Vec{X: 7000e3}, md3.Vec{Y: 7000, Z: 2000}
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	phys.Propagate(1234.5, 60)
+	if tm, _, _ := phys.State(); tm != 1234.5 {
+		t.Fatalf("propagated to %g", tm)
+	}
+	SBI, VBI := phys.Propagate(0, 60)
+	if d := md3.Norm(md3.Sub(SBI, SBI0)); d > 1e-3 {
+		t.Errorf("position after round trip differs by %gm", d)
+	}
+	if d := md3.Norm(md3.Sub(VBI, VBI0)); d > 1e-6 {
+		t.Errorf("velocity after round trip differs by %gm/s", d)
+	}
+}
+
+func TestSTM(t *testing.T) {
+	earth := NewEarth()
+	SBI0, VBI0 := md3.Vec{X: 7000e3, Y: 100e3}, md3.Vec{X: -100, Y: 6200, Z: 4300}
+	const tf, maxStep = 3000., 30.
+	for _, test := range []struct {
+		name   string
+		method IntegrationMethod
+		drag   bool
+		tol    float64
+	}{
+		{name: "J2", method: MethodRKN1210, tol: 1e-6},
+		{name: "J2+drag", method: MethodGRKN54, drag: true, tol: 1e-5},
+	} {
+		newPhys := func(SBI, VBI md3.Vec) *PhysicsPointIntegrator {
+			coords := earth.GeocentricFromDegrees(0, 0, 0).Geodesic()
+			phys := NewPhysicsPointIntegrator(&coords, 0, SBI, VBI)
+			phys.SetMethod(test.method)
+			if test.drag {
+				phys.AddForceModel("drag", NewDrag(ExponentialAtmosphere{BaseAltitude: 600e3, BaseDensity: 1e-13, ScaleHeight: 70e3}, 1, 2.2, 10))
+			}
+			return phys
+		}
+		phys := newPhys(SBI0, VBI0)
+		phys.ResetSTM()
+		phys.Propagate(tf, maxStep)
+		stm := phys.STM()
+		// Compare with central differences of perturbed trajectories.
+		x0 := [6]float64{SBI0.X, SBI0.Y, SBI0.Z, VBI0.X, VBI0.Y, VBI0.Z}
+		for j := 0; j < 6; j++ {
+			h := 1.
+			if j >= 3 {
+				h = 1e-3
+			}
+			xp, xm := x0, x0
+			xp[j] += h
+			xm[j] -= h
+			SBIp, VBIp := newPhys(md3.Vec{X: xp[0], Y: xp[1], Z: xp[2]}, md3.Vec{X: xp[3], Y: xp[4], Z: xp[5]}).Propagate(tf, maxStep)
+			SBIm, VBIm := newPhys(md3.Vec{X: xm[0], Y: xm[1], Z: xm[2]}, md3.Vec{X: xm[3], Y: xm[4], Z: xm[5]}).Propagate(tf, maxStep)
+			dS, dV := md3.Sub(SBIp, SBIm), md3.Sub(VBIp, VBIm)
+			col := [6]float64{dS.X, dS.Y, dS.Z, dV.X, dV.Y, dV.Z}
+			for i := range col {
+				col[i] /= 2 * h
+				scale := 1.
+				if i >= 3 && j < 3 {
+					scale = 1e-3 // Velocity wrt position partials are small.
+				} else if i < 3 && j >= 3 {
+					scale = tf
+				}
+				if math.Abs(col[i]-stm[i][j]) > test.tol*scale {
+					t.Errorf("%s: STM[%d][%d] want %g, got %g", test.name, i, j, col[i], stm[i][j])
+				}
+			}
+		}
+		// Conservative dynamics have a symplectic STM: STMᵀ*J*STM = J.
+		if test.drag {
+			continue
+		}
+		for i := 0; i < 6; i++ {
+			for j := 0; j < 6; j++ {
+				var got float64
+				for k := 0; k < 3; k++ {
+					got += stm[k][i]*stm[k+3][j] - stm[k+3][i]*stm[k][j]
+				}
+				want := 0.
+				if j == i+3 {
+					want = 1
+				} else if i == j+3 {
+					want = -1
+				}
+				if math.Abs(got-want) > 1e-6 {
+					t.Errorf("%s: STM not symplectic at (%d,%d): %g", test.name, i, j, got)
+				}
+			}
+		}
+	}
+}
+
+func TestSTMEventRewind(t *testing.T) {
+	earth := NewEarth()
+	start := earth.GeocentricFromHASL(0, 0, 100)
+	SBI0, _ := start.InertialCoords(0)
+	newPhys := func() *PhysicsPointIntegrator {
+		coords := start
+		phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, md3.Vec{})
+		phys.ResetSTM()
+		return phys
+	}
+	// The impact is found within the first step so the trajectory is a single step to the impact time.
+	phys := newPhys()
+	impact, ok := phys.FindImpact(ConstantTerrain(0), 10, 10, 1e-6, md3.Vec{})
+	if !ok {
+		t.Fatal("impact not found")
+	}
+	want := newPhys()
+	want.Step(impact.T, md3.Vec{})
+	if got, want := phys.STM(), want.STM(); got != want {
+		t.Errorf("STM after bisection includes trial steps:\nwant %v\ngot  %v", want, got)
+	}
+}
@@ -0,0 +1,140 @@
+package gnco
+
+import (
+	"errors"
+	"math"
+)
+
+// WorldConfig defines the physical parameters of a planet or moon. Use [NewWorld] to create a [World] from it.
+type WorldConfig struct {
+	GM             float64 // Gravitational parameter [m^3.s^-2]. The world's mass is derived from it.
+	SemiMajorAxis  float64 // Equatorial radius of reference ellipsoid [m].
+	Flattening     float64 // Flattening of reference ellipsoid [Adim]. 0 is a perfect sphere.
+	Radius         float64 // Radius of reference sphere [m]. If zero the volumetric mean radius of the ellipsoid is used.
+	SeaLevelRadius float64 // Mean sea level radius [m]. Zero for worlds without sea, in which case HASL equals elevation.
+	Rotation       float64 // Angular rotation of world w.r.t inertial frame [rad/s]. Negative for retrograde rotation.
+	CelestialLong  float64 // Celestial longitude of prime meridian w.r.t inertial X axis at start of epoch [rad].
+	// Un-normalised zonal harmonics.
+	J2, J3, J4 float64
+	// Geoid models mean sea level. See [World.Geoid].
+	Geoid Geoid
+}
+
+// Validate checks the configuration describes a physically possible world.
+func (cfg WorldConfig) Validate() error {
+	switch {
+	case !(cfg.GM > 0) || math.IsInf(cfg.GM, 0):
+		return errors.New("world gravitational parameter must be positive")
+	case !(cfg.SemiMajorAxis > 0) || math.IsInf(cfg.SemiMajorAxis, 0):
+		return errors.New("world semi-major axis must be positive")
+	case !(cfg.Flattening >= 0 && cfg.Flattening < 1):
+		return errors.New("world flattening must be in range [0, 1)")
+	case cfg.Radius < 0 || cfg.Radius > cfg.SemiMajorAxis:
+		return errors.New("world radius must be non-negative and not exceed semi-major axis")
+	case !(cfg.SeaLevelRadius >= 0) || math.IsInf(cfg.SeaLevelRadius, 0):
+		return errors.New("world sea level radius must be non-negative")
+	case math.IsNaN(cfg.Rotation) || math.IsInf(cfg.Rotation, 0):
+		return errors.New("world rotation must be finite")
+	case math.IsNaN(cfg.CelestialLong) || math.IsInf(cfg.CelestialLong, 0):
+		return errors.New("world celestial longitude must be finite")
+	case math.IsNaN(cfg.J2) || math.IsInf(cfg.J2, 0) || math.IsNaN(cfg.J3) || math.IsInf(cfg.J3, 0) ||
+		math.IsNaN(cfg.J4) || math.IsInf(cfg.J4, 0):
+		return errors.New("world zonal harmonics must be finite")
+	}
+	return nil
+}
+
+// NewWorld returns a new world defined by cfg after validating it.
+func NewWorld(cfg WorldConfig) (*World, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	radius := cfg.Radius
+	if radius == 0 {
+		radius = cfg.SemiMajorAxis * math.Cbrt(1-cfg.Flattening)
+	}
+	a := cfg.SemiMajorAxis
+	return &World{
+		Mass:           cfg.GM / bigG,
+		C20:            -cfg.J2 / math.Sqrt(5),
+		SemiMajorAxis:  a,
+		Rotation:       cfg.Rotation,
+		Radius:         radius,
+		seaLevelRadius: cfg.SeaLevelRadius,
+		flattening:     cfg.Flattening,
+		celestialLong:  cfg.CelestialLong,
+
+		Ke: 60 * math.Sqrt(cfg.GM/(a*a*a)),
+		J2: cfg.J2,
+		J3: cfg.J3,
+		J4: cfg.J4,
+
+		Geoid: cfg.Geoid,
+	}, nil
+}
+
+// Config returns the configuration that defines w.
+func (w *World) Config() WorldConfig {
+	return WorldConfig{
+		GM:             w.G(),
+		SemiMajorAxis:  w.SemiMajorAxis,
+		Flattening:     w.flattening,
+		Radius:         w.Radius,
+		SeaLevelRadius: w.seaLevelRadius,
+		Rotation:       w.Rotation,
+		CelestialLong:  w.celestialLong,
+		J2:             w.J2,
+		J3:             w.J3,
+		J4:             w.J4,
+		Geoid:          w.Geoid,
+	}
+}
+
+// Flattening returns the flattening of w's reference ellipsoid [Adim].
+func (w *World) Flattening() float64 { return w.flattening }
+
+// MoonConfig returns the configuration of Earth's moon. Rotation is synchronous with its orbit.
+// Zonal harmonics from the GRAIL GRGM900C gravity model.
+func MoonConfig() WorldConfig {
+	return WorldConfig{
+		GM:            4.9028001e12,
+		SemiMajorAxis: 1738.1e3,
+		Flattening:    0.0012,
+		Radius:        1737.4e3,
+		Rotation:      2.6616995e-6, // Sidereal period of 27.321661 days.
+		J2:            2.0330530e-4,
+		J3:            8.4597e-6,
+		J4:            -9.6e-6,
+	}
+}
+
+// MarsConfig returns the configuration of Mars. Mars has no sea so heights above
+// sea level are equivalent to elevation above the reference sphere.
+// Zonal harmonics from the JPL MRO120D gravity model.
+func MarsConfig() WorldConfig {
+	return WorldConfig{
+		GM:            4.282837e13,
+		SemiMajorAxis: 3396.2e3,
+		Flattening:    0.00589,
+		Radius:        3389.5e3,
+		Rotation:      7.088218e-5, // Sidereal day of 24.6229 hours.
+		J2:            1.96045e-3,
+		J3:            3.145e-5,
+		J4:            -1.5377e-5,
+	}
+}
+
+// NewMoon returns Earth's moon. See [MoonConfig].
+func NewMoon() *World { return mustNewWorld(MoonConfig()) }
+
+// NewMars returns the planet Mars. See [MarsConfig].
+func NewMars() *World { return mustNewWorld(MarsConfig()) }
+
+func mustNewWorld(cfg WorldConfig) *World {
+	w, err := NewWorld(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
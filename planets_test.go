@@ -0,0 +1,46 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+)
+
+func TestNewWorld(t *testing.T) {
+	earth := NewEarth()
+	cfg := earth.Config()
+	w, err := NewWorld(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !md1.EqualWithinAbs(w.G(), earth.G(), 1) || !md1.EqualWithinAbs(w.C20, earth.C20, 1e-10) {
+		t.Errorf("world from earth config mismatch: G=%g/%g C20=%g/%g", w.G(), earth.G(), w.C20, earth.C20)
+	}
+	if !md1.EqualWithinAbs(w.Ke, earth.Ke, 1e-6) {
+		t.Errorf("Ke: want %g, got %g", earth.Ke, w.Ke)
+	}
+	earth.Geoid = &GeoidGrid{}
+	if w, _ := NewWorld(earth.Config()); w.Geoid != earth.Geoid {
+		t.Error("geoid lost in config round trip")
+	}
+
+	for _, bad := range []func(*WorldConfig){
+		func(c *WorldConfig) { c.GM = 0 },
+		func(c *WorldConfig) { c.SemiMajorAxis = -1 },
+		func(c *WorldConfig) { c.Flattening = 1 },
+		func(c *WorldConfig) { c.Radius = 2 * c.SemiMajorAxis },
+		func(c *WorldConfig) { c.Rotation = math.Inf(1) },
+		func(c *WorldConfig) { c.SeaLevelRadius = -1 },
+		func(c *WorldConfig) { c.CelestialLong = math.Inf(-1) },
+		func(c *WorldConfig) { c.J2 = math.NaN() },
+		func(c *WorldConfig) { c.J4 = math.Inf(1) },
+	} {
+		cfg := MarsConfig()
+		bad(&cfg)
+		_, err := NewWorld(cfg)
+		if err == nil {
+			t.Errorf("expected error for config %+v", cfg)
+		}
+	}
+}
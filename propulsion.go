@@ -0,0 +1,231 @@
+package gnco
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/soypat/geometry/md3"
+)
+
+const (
+	// StandardGravity is the standard acceleration of gravity used to define specific impulse [m.s^-2].
+	StandardGravity = 9.80665
+	// SeaLevelPressure is the standard atmospheric pressure at sea level [Pa].
+	SeaLevelPressure = 101325.
+)
+
+// ThrustCurve describes the vacuum thrust of an engine over time since ignition.
+type ThrustCurve interface {
+	// VacuumThrust returns the vacuum thrust [N] at time since ignition t [s].
+	// It is zero before ignition and after burnout.
+	VacuumThrust(t float64) float64
+	// Impulse returns the total vacuum impulse [N.s] delivered from ignition up to time since ignition t [s].
+	Impulse(t float64) float64
+	// BurnTime returns the duration of the burn [s].
+	BurnTime() float64
+}
+
+var (
+	_ ThrustCurve = ConstantThrust{}
+	_ ThrustCurve = (*ThrustTable)(nil)
+	_ ForceModel  = (*Propulsion)(nil)
+)
+
+// ConstantThrust is a thrust curve of constant vacuum thrust during a burn.
+type ConstantThrust struct {
+	Thrust   float64 // Vacuum thrust [N].
+	Duration float64 // Burn time [s].
+}
+
+// VacuumThrust returns the constant thrust during the burn. It implements [ThrustCurve].
+func (c ConstantThrust) VacuumThrust(t float64) float64 {
+	if t < 0 || t >= c.Duration {
+		return 0
+	}
+	return c.Thrust
+}
+
+// Impulse returns the impulse delivered up to time since ignition t. It implements [ThrustCurve].
+func (c ConstantThrust) Impulse(t float64) float64 {
+	return c.Thrust * math.Min(math.Max(t, 0), c.Duration)
+}
+
+// BurnTime returns the burn duration. It implements [ThrustCurve].
+func (c ConstantThrust) BurnTime() float64 { return c.Duration }
+
+// ThrustTable is a thrust curve linearly interpolated between tabulated points.
+type ThrustTable struct {
+	time, thrust []float64
+	// impulse is the cumulative impulse at each tabulated time.
+	impulse []float64
+}
+
+// NewThrustTable returns a thrust curve interpolating vacuum thrust [N] at strictly increasing times
+// since ignition [s]. The first time must be zero or greater. Thrust is zero outside the table's time range.
+func NewThrustTable(time, thrust []float64) (*ThrustTable, error) {
+	if len(time) != len(thrust) {
+		return nil, errors.New("thrust table time and thrust length mismatch")
+	} else if len(time) < 2 {
+		return nil, errors.New("thrust table requires at least two points")
+	} else if time[0] < 0 {
+		return nil, errors.New("thrust table starts before ignition")
+	}
+	impulse := make([]float64, len(time))
+	for i := range time {
+		if thrust[i] < 0 || math.IsNaN(thrust[i]) {
+			return nil, errors.New("negative or NaN thrust in thrust table")
+		}
+		if i == 0 {
+			continue
+		}
+		if !(time[i] > time[i-1]) {
+			return nil, errors.New("thrust table times not strictly increasing")
+		}
+		impulse[i] = impulse[i-1] + (time[i]-time[i-1])*(thrust[i]+thrust[i-1])/2
+	}
+	return &ThrustTable{
+		time:    slices.Clone(time),
+		thrust:  slices.Clone(thrust),
+		impulse: impulse,
+	}, nil
+}
+
+// VacuumThrust returns the interpolated thrust at time since ignition t. It implements [ThrustCurve].
+func (tt *ThrustTable) VacuumThrust(t float64) float64 {
+	i, ok := tt.segment(t)
+	if !ok {
+		return 0
+	}
+	return tt.interp(i, t)
+}
+
+// Impulse returns the impulse delivered up to time since ignition t. It implements [ThrustCurve].
+func (tt *ThrustTable) Impulse(t float64) float64 {
+	n := len(tt.time)
+	if t <= tt.time[0] {
+		return 0
+	} else if t >= tt.time[n-1] {
+		return tt.impulse[n-1]
+	}
+	i, _ := tt.segment(t)
+	// Trapezoid is exact for linear interpolation.
+	return tt.impulse[i] + (t-tt.time[i])*(tt.thrust[i]+tt.interp(i, t))/2
+}
+
+// BurnTime returns the time of the last tabulated point. It implements [ThrustCurve].
+func (tt *ThrustTable) BurnTime() float64 { return tt.time[len(tt.time)-1] }
+
+// segment returns index i such that time[i] <= t < time[i+1].
+func (tt *ThrustTable) segment(t float64) (int, bool) {
+	if t < tt.time[0] || t >= tt.time[len(tt.time)-1] {
+		return 0, false
+	}
+	i, found := slices.BinarySearch(tt.time, t)
+	if !found {
+		i--
+	}
+	return i, true
+}
+
+func (tt *ThrustTable) interp(i int, t float64) float64 {
+	frac := (t - tt.time[i]) / (tt.time[i+1] - tt.time[i])
+	return tt.thrust[i] + frac*(tt.thrust[i+1]-tt.thrust[i])
+}
+
+// Engine is a rocket engine defined by its vacuum thrust curve and specific impulse.
+// Thrust at ambient pressure P is given by the vacuum thrust minus P times the nozzle exit area.
+type Engine struct {
+	Curve    ThrustCurve
+	Isp      float64 // Vacuum specific impulse [s].
+	ExitArea float64 // Nozzle exit area [m^2].
+}
+
+// MassFlow returns the propellant mass flow [kg.s^-1] at time since ignition t [s].
+// Mass flow does not depend on ambient pressure.
+func (e Engine) MassFlow(t float64) float64 {
+	return e.Curve.VacuumThrust(t) / (e.Isp * StandardGravity)
+}
+
+// PropellantConsumed returns the propellant mass [kg] burnt from ignition up to time since ignition t [s].
+func (e Engine) PropellantConsumed(t float64) float64 {
+	return e.Curve.Impulse(t) / (e.Isp * StandardGravity)
+}
+
+// Thrust returns the thrust [N] at time since ignition t [s] at ambient pressure [Pa].
+func (e Engine) Thrust(t, ambientPressure float64) float64 {
+	vac := e.Curve.VacuumThrust(t)
+	if vac == 0 {
+		return 0
+	}
+	return math.Max(vac-ambientPressure*e.ExitArea, 0)
+}
+
+// SeaLevelThrust returns the thrust [N] at time since ignition t [s] at standard sea level pressure.
+func (e Engine) SeaLevelThrust(t float64) float64 { return e.Thrust(t, SeaLevelPressure) }
+
+// Propulsion is the force model of a body propelled by an [Engine] whose mass decreases as propellant is burnt.
+// Mass flow depends only on time since ignition so the body's mass is integrated exactly alongside
+// position and the acceleration F/m is correct at every integrator stage.
+// Ambient pressure is obtained from [InternationalStandardAtmosphere] at the body's height above
+// the reference ellipsoid. Thrust is discontinuous at ignition and burnout which limits the accuracy
+// of integrator steps that contain or end on them. Use small steps around [Propulsion.Burnout].
+type Propulsion struct {
+	Engine Engine
+	// IgnitionTime is the epoch time at which the engine ignites [s].
+	IgnitionTime float64
+	// InitialMass is the total mass of the body at ignition [kg].
+	InitialMass float64
+	// Direction returns the thrust direction in inertial frame. It need not be normalized.
+	// If nil thrust is along the velocity relative to the world's co-rotating atmosphere,
+	// or radially outward if the body is at rest with respect to the world.
+	Direction func(s *ForceState) md3.Vec
+}
+
+// Mass returns the mass of the body at epoch time t [kg]. It panics if the engine has burnt
+// the whole InitialMass by time t, which means the thrust curve is inconsistent with the body.
+func (p *Propulsion) Mass(t float64) float64 {
+	m := p.InitialMass - p.Engine.PropellantConsumed(t-p.IgnitionTime)
+	if !(m > 0) {
+		panic("propellant consumed exceeds initial mass")
+	}
+	return m
+}
+
+// Burnout returns the epoch time at which the engine burns out [s].
+func (p *Propulsion) Burnout() float64 {
+	return p.IgnitionTime + p.Engine.Curve.BurnTime()
+}
+
+// Thrust returns the thrust [N] of the engine at the body state s.
+func (p *Propulsion) Thrust(s *ForceState) float64 {
+	tb := s.T - p.IgnitionTime
+	if p.Engine.Curve.VacuumThrust(tb) == 0 {
+		return 0 // Avoid height calculation when engine is not burning.
+	}
+	_, P, _ := InternationalStandardAtmosphere(ellipsoidalHeight(s), 288.15)
+	return p.Engine.Thrust(tb, P)
+}
+
+// Accel returns the thrust acceleration in inertial frame [m.s^-2]. It implements [ForceModel].
+func (p *Propulsion) Accel(s *ForceState) md3.Vec {
+	return p.accel(s, p.Thrust(s), p.Direction)
+}
+
+// accel returns the acceleration produced by thrust F [N] along the thrust direction returned by direction.
+// If direction is nil the default direction of [Propulsion.Direction] is used.
+func (p *Propulsion) accel(s *ForceState, F float64, direction func(s *ForceState) md3.Vec) md3.Vec {
+	if F == 0 {
+		return md3.Vec{}
+	}
+	var dir md3.Vec
+	if direction != nil {
+		dir = direction(s)
+	} else {
+		dir = RelativeVelocity(s)
+		if md3.Norm(dir) < 1e-6 {
+			dir = s.SBI
+		}
+	}
+	return md3.Scale(F/(p.Mass(s.T)*md3.Norm(dir)), dir)
+}
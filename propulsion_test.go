@@ -0,0 +1,111 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestThrustTable(t *testing.T) {
+	_, err := NewThrustTable([]float64{0, 1, 1}, []float64{0, 10, 0})
+	if err == nil {
+		t.Error("expected error for non increasing times")
+	}
+	_, err = NewThrustTable([]float64{0, 1}, []float64{0, -10})
+	if err == nil {
+		t.Error("expected error for negative thrust")
+	}
+	// Triangular thrust curve peaking at 2s.
+	tt, err := NewThrustTable([]float64{0, 2, 4}, []float64{0, 100, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		t, thrust, impulse float64
+	}{
+		{t: -1, thrust: 0, impulse: 0},
+		{t: 1, thrust: 50, impulse: 25},
+		{t: 2, thrust: 100, impulse: 100},
+		{t: 3, thrust: 50, impulse: 175},
+		{t: 5, thrust: 0, impulse: 200},
+	} {
+		if got := tt.VacuumThrust(test.t); !md1.EqualWithinAbs(got, test.thrust, 1e-12) {
+			t.Errorf("t=%g: want thrust %g, got %g", test.t, test.thrust, got)
+		}
+		if got := tt.Impulse(test.t); !md1.EqualWithinAbs(got, test.impulse, 1e-12) {
+			t.Errorf("t=%g: want impulse %g, got %g", test.t, test.impulse, got)
+		}
+	}
+	if tt.BurnTime() != 4 {
+		t.Errorf("want burn time 4, got %g", tt.BurnTime())
+	}
+}
+
+func TestEngineThrust(t *testing.T) {
+	e := Engine{Curve: ConstantThrust{Thrust: 1e6, Duration: 100}, Isp: 300, ExitArea: 1}
+	if got := e.SeaLevelThrust(50); got != 1e6-SeaLevelPressure {
+		t.Errorf("want sea level thrust %g, got %g", 1e6-SeaLevelPressure, got)
+	}
+	if got := e.Thrust(50, 0); got != 1e6 {
+		t.Errorf("want vacuum thrust %g, got %g", 1e6, got)
+	}
+	if got := e.Thrust(150, 0); got != 0 {
+		t.Errorf("want no thrust after burnout, got %g", got)
+	}
+	wantFlow := 1e6 / (300 * StandardGravity)
+	if got := e.MassFlow(0); !md1.EqualWithinAbs(got, wantFlow, 1e-9) {
+		t.Errorf("want mass flow %g, got %g", wantFlow, got)
+	}
+	if got := e.PropellantConsumed(1000); !md1.EqualWithinAbs(got, 100*wantFlow, 1e-9) {
+		t.Errorf("want propellant consumed %g, got %g", 100*wantFlow, got)
+	}
+}
+
+func TestPropulsionRocketEquation(t *testing.T) {
+	// Without gravity the velocity gained must match Tsiolkovsky's rocket equation.
+	const (
+		thrust, isp, duration = 1000., 300., 100.
+		m0, tIgnite, dt       = 100., 10., 1.
+	)
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	SBI0 := md3.Vec{X: earth.SemiMajorAxis + 1000e3} // Negligible ambient pressure.
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, md3.Vec{})
+	phys.RemoveForceModel("gravity")
+	prop := &Propulsion{
+		Engine:       Engine{Curve: ConstantThrust{Thrust: thrust, Duration: duration}, Isp: isp, ExitArea: 0.1},
+		IgnitionTime: tIgnite,
+		InitialMass:  m0,
+		Direction:    func(s *ForceState) md3.Vec { return md3.Vec{Y: 2, Z: 2} },
+	}
+	phys.AddForceModel("thrust", prop)
+	var VBI md3.Vec
+	for i := 0; i < 150; i++ {
+		_, _, VBI = phys.Step(dt, md3.Vec{})
+	}
+	m1 := prop.Mass(150)
+	wantMass := m0 - thrust*duration/(isp*StandardGravity)
+	if !md1.EqualWithinAbs(m1, wantMass, 1e-9) {
+		t.Errorf("want final mass %g, got %g", wantMass, m1)
+	}
+	if prop.Burnout() != tIgnite+duration {
+		t.Errorf("want burnout at %g, got %g", tIgnite+duration, prop.Burnout())
+	}
+	wantDV := isp * StandardGravity * math.Log(m0/m1)
+	wantVBI := md3.Scale(wantDV/math.Sqrt2, md3.Vec{Y: 1, Z: 1})
+	// Thrust is discontinuous at burnout which limits integration accuracy.
+	if !md3.EqualElem(VBI, wantVBI, wantDV*1e-4) {
+		t.Errorf("want velocity %v, got %v", wantVBI, VBI)
+	}
+
+	// A thrust curve that burns more than the body's mass is an error.
+	prop.InitialMass = (m0 - wantMass) / 2
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic when burning more than the initial mass")
+		}
+	}()
+	prop.Mass(150)
+}
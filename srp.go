@@ -0,0 +1,131 @@
+package gnco
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+const (
+	// AstronomicalUnit is the mean distance from the Earth to the Sun [m].
+	AstronomicalUnit = 149597870700.
+	// SolarPressureAU is the solar radiation pressure on a perfect absorber at 1 AU [N.m^-2].
+	SolarPressureAU = 4.56e-6
+	// SunRadius is the radius of the Sun's photosphere [m].
+	SunRadius = 696000e3
+)
+
+// ShadowModel selects how the shadow cast by a world on an orbiting body is modelled.
+type ShadowModel uint8
+
+const (
+	// ShadowConical models the world's umbra and penumbra as cones, accounting for partial eclipses.
+	ShadowConical ShadowModel = iota
+	// ShadowCylindrical models the world's shadow as a cylinder behind the world with no penumbra.
+	ShadowCylindrical
+	// ShadowNone disables eclipses. The body is always in sunlight.
+	ShadowNone
+)
+
+// SolarRadiationPressure is a cannonball solar radiation pressure force model. The Sun
+// position is obtained from the low precision analytic ephemeris [SunPositionJ2000].
+type SolarRadiationPressure struct {
+	Area   float64 // Cross-sectional area of the body facing the Sun [m^2].
+	Cr     float64 // Reflectivity coefficient [Adim]. 1 for a perfect absorber, 2 for a perfect specular reflector.
+	Mass   float64 // Mass of the body [kg].
+	Shadow ShadowModel
+	sun    *ThirdBody
+}
+
+var _ ForceModel = (*SolarRadiationPressure)(nil)
+
+// NewSolarRadiationPressure returns a solar radiation pressure force model with a conical shadow model
+// for an integrator whose epoch time zero corresponds to the Julian date epochJD.
+func NewSolarRadiationPressure(w *World, epochJD, area, cr, mass float64) *SolarRadiationPressure {
+	return &SolarRadiationPressure{
+		Area: area,
+		Cr:   cr,
+		Mass: mass,
+		sun:  NewSunPerturbation(w, epochJD),
+	}
+}
+
+// SunPositionInertial returns the position of the Sun in the world's inertial frame at epoch time t [m].
+func (srp *SolarRadiationPressure) SunPositionInertial(t float64) md3.Vec {
+	return srp.sun.PositionInertial(t)
+}
+
+// Illumination returns the fraction of the solar disk visible from inertial position SBI at epoch time t.
+// 1 is full sunlight and 0 is full eclipse (umbra).
+func (srp *SolarRadiationPressure) Illumination(t float64, SBI md3.Vec) float64 {
+	sSun := srp.sun.PositionInertial(t)
+	radius := srp.sun.w.Radius
+	switch srp.Shadow {
+	case ShadowConical:
+		return ConicalShadow(SBI, sSun, radius)
+	case ShadowCylindrical:
+		return CylindricalShadow(SBI, sSun, radius)
+	case ShadowNone:
+		return 1
+	default:
+		panic("unknown shadow model")
+	}
+}
+
+// Accel returns the solar radiation pressure acceleration in inertial frame [m.s^-2]. It implements [ForceModel].
+func (srp *SolarRadiationPressure) Accel(s *ForceState) md3.Vec {
+	nu := srp.Illumination(s.T, s.SBI)
+	if nu == 0 {
+		return md3.Vec{}
+	}
+	// Direction away from the Sun.
+	d := md3.Sub(s.SBI, srp.sun.PositionInertial(s.T))
+	dnorm := md3.Norm(d)
+	auRatio := AstronomicalUnit / dnorm
+	pressure := nu * SolarPressureAU * auRatio * auRatio
+	return md3.Scale(pressure*srp.Cr*srp.Area/(srp.Mass*dnorm), d)
+}
+
+// CylindricalShadow returns 0 if the body at SBI is within the cylindrical shadow cast by a world of
+// given radius lit by the Sun at sSun, and 1 otherwise. Positions are relative to the world's center.
+func CylindricalShadow(SBI, sSun md3.Vec, radius float64) float64 {
+	uSun := md3.Unit(sSun)
+	along := md3.Dot(SBI, uSun)
+	if along >= 0 {
+		return 1 // Body is on the sunlit side of the world.
+	}
+	perp := md3.Sub(SBI, md3.Scale(along, uSun))
+	if md3.Norm(perp) < radius {
+		return 0
+	}
+	return 1
+}
+
+// ConicalShadow returns the fraction of the solar disk visible from the body at SBI when occulted
+// by a world of given radius, accounting for umbra and penumbra. The Sun is at sSun and positions are
+// relative to the world's center. See Montenbruck, Gill - Satellite Orbits (3.4.2).
+func ConicalShadow(SBI, sSun md3.Vec, radius float64) float64 {
+	d := md3.Sub(sSun, SBI) // Body to Sun.
+	dnorm, rnorm := md3.Norm(d), md3.Norm(SBI)
+	if rnorm <= radius {
+		return 0 // Inside the world.
+	}
+	a := math.Asin(math.Min(SunRadius/dnorm, 1)) // Apparent radius of Sun.
+	b := math.Asin(radius / rnorm)               // Apparent radius of world.
+	// Apparent separation between centers of Sun and world.
+	c := math.Acos(md1.Clamp(-md3.Dot(SBI, d)/(rnorm*dnorm), -1, 1))
+	switch {
+	case c >= a+b:
+		return 1 // No occultation.
+	case c <= b-a:
+		return 0 // Total eclipse, umbra.
+	case c <= a-b:
+		return 1 - (b*b)/(a*a) // Annular eclipse.
+	}
+	// Partial eclipse, penumbra.
+	x := (c*c + a*a - b*b) / (2 * c)
+	y := math.Sqrt(math.Max(a*a-x*x, 0))
+	area := a*a*math.Acos(md1.Clamp(x/a, -1, 1)) + b*b*math.Acos(md1.Clamp((c-x)/b, -1, 1)) - c*y
+	return 1 - area/(math.Pi*a*a)
+}
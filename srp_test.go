@@ -0,0 +1,78 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestShadow(t *testing.T) {
+	const radius = 6378e3
+	sSun := md3.Vec{X: AstronomicalUnit}
+	for _, test := range []struct {
+		name       string
+		SBI        md3.Vec
+		cyl, conic float64
+	}{
+		{name: "sunlit", SBI: md3.Vec{X: 7000e3}, cyl: 1, conic: 1},
+		{name: "side", SBI: md3.Vec{Y: 7000e3}, cyl: 1, conic: 1},
+		{name: "behind", SBI: md3.Vec{X: -7000e3}, cyl: 0, conic: 0},
+		{name: "behind off axis", SBI: md3.Vec{X: -7000e3, Y: radius + 100e3}, cyl: 1, conic: 1},
+	} {
+		if got := CylindricalShadow(test.SBI, sSun, radius); got != test.cyl {
+			t.Errorf("%s: cylindrical want %g, got %g", test.name, test.cyl, got)
+		}
+		if got := ConicalShadow(test.SBI, sSun, radius); !md1.EqualWithinAbs(got, test.conic, 1e-9) {
+			t.Errorf("%s: conical want %g, got %g", test.name, test.conic, got)
+		}
+	}
+	// Crossing the shadow boundary at GEO the illumination decreases monotonically through the penumbra.
+	const rGEO = 42164e3
+	prev := 1.0
+	partial := false
+	for y := radius + 300e3; y > radius-300e3; y -= 1e3 {
+		nu := ConicalShadow(md3.Vec{X: -rGEO, Y: y}, sSun, radius)
+		if nu > prev+1e-12 {
+			t.Fatalf("illumination increased entering shadow at y=%g: %g > %g", y, nu, prev)
+		}
+		partial = partial || (nu > 0 && nu < 1)
+		prev = nu
+	}
+	if !partial || prev != 0 {
+		t.Errorf("expected penumbra crossing into umbra, partial=%v final=%g", partial, prev)
+	}
+}
+
+func TestSolarRadiationPressure(t *testing.T) {
+	earth := NewEarth()
+	srp := NewSolarRadiationPressure(earth, JulianDateJ2000, 10, 1.5, 1000)
+	sSun := srp.SunPositionInertial(0)
+	uSun := md3.Unit(sSun)
+	SBI := md3.Scale(7000e3, uSun) // Sunlit side.
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI, md3.Vec{})
+	phys.AddForceModel("srp", srp)
+	forces := phys.Forces(nil)
+	got := forces[1].AccelInertial
+	auRatio := AstronomicalUnit / md3.Norm(md3.Sub(sSun, SBI))
+	want := SolarPressureAU * auRatio * auRatio * 1.5 * 10 / 1000
+	if !md1.EqualWithinAbs(md3.Norm(got), want, want*1e-9) {
+		t.Errorf("magnitude: want %g, got %g", want, md3.Norm(got))
+	}
+	if md3.Cos(got, uSun) > -0.999999 {
+		t.Errorf("acceleration should point away from Sun, got %v", got)
+	}
+	// In eclipse there is no radiation pressure.
+	for _, shadow := range []ShadowModel{ShadowConical, ShadowCylindrical} {
+		srp.Shadow = shadow
+		if a := srp.Accel(&ForceState{SBI: md3.Scale(-7000e3, uSun)}); a != (md3.Vec{}) {
+			t.Errorf("shadow %d: want no acceleration in eclipse, got %v", shadow, a)
+		}
+	}
+	srp.Shadow = ShadowNone
+	if a := srp.Accel(&ForceState{SBI: md3.Scale(-7000e3, uSun)}); math.Abs(md3.Norm(a)-want) > want*1e-3 {
+		t.Errorf("no shadow: want %g, got %g", want, md3.Norm(a))
+	}
+}
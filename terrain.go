@@ -0,0 +1,230 @@
+package gnco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Terrain models the surface of a world.
+type Terrain interface {
+	// Height returns the terrain orthometric height (height above mean sea level) [m]
+	// at a geodetic latitude and longitude [rad].
+	Height(geodeticLat, long float64) float64
+}
+
+var (
+	_ Terrain = ConstantTerrain(0)
+	_ Terrain = TerrainFunc(nil)
+	_ Terrain = SinusoidalTerrain{}
+	_ Terrain = (*TerrainGrid)(nil)
+)
+
+// ConstantTerrain is a terrain of constant orthometric height [m]. A ConstantTerrain of 0 is sea level.
+type ConstantTerrain float64
+
+// Height returns the constant height of the terrain [m].
+func (c ConstantTerrain) Height(geodeticLat, long float64) float64 { return float64(c) }
+
+// TerrainFunc adapts an ordinary function to the [Terrain] interface.
+type TerrainFunc func(geodeticLat, long float64) float64
+
+// Height returns f(geodeticLat, long).
+func (f TerrainFunc) Height(geodeticLat, long float64) float64 { return f(geodeticLat, long) }
+
+// SinusoidalTerrain is an analytic terrain useful for testing impact detection against a
+// smooth surface with hills and valleys:
+//
+//	height = Mean + Amplitude*sin(2*pi*lat/Wavelength)*sin(2*pi*long/Wavelength)
+type SinusoidalTerrain struct {
+	Mean       float64 // Mean height of terrain [m].
+	Amplitude  float64 // Height of hills above mean height [m].
+	Wavelength float64 // Angular distance between hills [rad].
+}
+
+// Height returns the terrain height at the geodetic latitude and longitude [m].
+func (s SinusoidalTerrain) Height(geodeticLat, long float64) float64 {
+	k := 2 * math.Pi / s.Wavelength
+	return s.Mean + s.Amplitude*math.Sin(k*geodeticLat)*math.Sin(k*long)
+}
+
+// TerrainGrid is a regular latitude/longitude grid of terrain orthometric heights such as
+// an SRTM tile or a digital elevation model.
+type TerrainGrid struct {
+	// Interpolation method used by Height. Defaults to bilinear.
+	Interpolation GridInterpolation
+	grid          latLongGrid
+}
+
+// NewTerrainGrid returns a terrain grid with orthometric heights [m] stored row-major with nlong values per row.
+// The first node is at lat0Deg, long0Deg and subsequent nodes are spaced by dlatDeg and dlongDeg.
+// dlatDeg may be negative to describe grids stored from North to South.
+func NewTerrainGrid(lat0Deg, long0Deg, dlatDeg, dlongDeg float64, nlat, nlong int, heights []float64) (*TerrainGrid, error) {
+	grid, err := newLatLongGrid(lat0Deg, long0Deg, dlatDeg, dlongDeg, nlat, nlong, heights)
+	if err != nil {
+		return nil, err
+	}
+	return &TerrainGrid{grid: grid}, nil
+}
+
+// LoadRawTerrainGrid reads a headerless grid of signed 16 bit heights [m] with the given byte order.
+// Grid layout is the same as described by [NewTerrainGrid]. Samples equal to voidValue are
+// considered missing data and set to sea level.
+func LoadRawTerrainGrid(r io.Reader, order binary.ByteOrder, voidValue int16, lat0Deg, long0Deg, dlatDeg, dlongDeg float64, nlat, nlong int) (*TerrainGrid, error) {
+	if nlat < 0 || nlong < 0 {
+		return nil, errors.New("negative terrain grid size")
+	}
+	raw := make([]int16, nlat*nlong)
+	err := binary.Read(r, order, raw)
+	if err != nil {
+		return nil, fmt.Errorf("reading raw terrain grid: %w", err)
+	}
+	heights := make([]float64, len(raw))
+	for i, h := range raw {
+		if h != voidValue {
+			heights[i] = float64(h)
+		}
+	}
+	return NewTerrainGrid(lat0Deg, long0Deg, dlatDeg, dlongDeg, nlat, nlong, heights)
+}
+
+// LoadSRTMHGT reads an SRTM .hgt tile of 1 or 3 arc-second resolution. The tile's
+// southwest corner is parsed from its file name, i.e: "S35W059.hgt". Voids are set to sea level.
+func LoadSRTMHGT(r io.Reader, filename string) (*TerrainGrid, error) {
+	south, west, err := srtmTileOrigin(filename)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	switch len(data) {
+	case 1201 * 1201 * 2:
+		n = 1201 // SRTM3.
+	case 3601 * 3601 * 2:
+		n = 3601 // SRTM1.
+	default:
+		return nil, fmt.Errorf("unexpected SRTM tile size %d bytes", len(data))
+	}
+	const srtmVoid = -32768
+	spacing := 1 / float64(n-1)
+	return LoadRawTerrainGrid(bytes.NewReader(data), binary.BigEndian, srtmVoid, south+1, west, -spacing, spacing, n, n)
+}
+
+// srtmTileOrigin parses the southwest corner of an SRTM tile name such as "N34W119.hgt" [deg].
+func srtmTileOrigin(filename string) (southDeg, westDeg float64, err error) {
+	name := strings.ToUpper(strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	if len(name) != 7 {
+		return 0, 0, fmt.Errorf("bad SRTM tile name %q", filename)
+	}
+	lat, err1 := strconv.Atoi(name[1:3])
+	long, err2 := strconv.Atoi(name[4:7])
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("bad SRTM tile name %q", filename)
+	}
+	switch {
+	case name[0] == 'S':
+		lat = -lat
+	case name[0] != 'N':
+		return 0, 0, fmt.Errorf("bad SRTM tile latitude hemisphere %q", filename)
+	}
+	switch {
+	case name[3] == 'W':
+		long = -long
+	case name[3] != 'E':
+		return 0, 0, fmt.Errorf("bad SRTM tile longitude hemisphere %q", filename)
+	}
+	return float64(lat), float64(long), nil
+}
+
+// Height returns the terrain height [m] at the geodetic latitude and longitude [rad].
+// Points outside of a regional grid are clamped to its edges.
+func (t *TerrainGrid) Height(geodeticLat, long float64) float64 {
+	return t.grid.interpolate(t.Interpolation, geodeticLat, long)
+}
+
+// HeightAboveTerrain returns the height of g above terrain [m]. Negative values indicate g is below the terrain surface.
+func (g GeocentricCoords) HeightAboveTerrain(terrain Terrain) float64 {
+	lat, long, h := g.Geodetic()
+	return g.w.OrthometricHeight(lat, long, h) - terrain.Height(lat, long)
+}
+
+// Impact describes the intersection of a trajectory with a [Terrain].
+type Impact struct {
+	T      float64          // Epoch time of impact [s].
+	SBI    md3.Vec          // Inertial position at impact [m].
+	VBI    md3.Vec          // Inertial velocity at impact [m/s].
+	Coords GeocentricCoords // Coordinates of impact point.
+}
+
+// FindImpact steps phys in steps of dt with the given external acceleration until the trajectory
+// crosses the terrain surface from above or the epoch time reaches tMax. When a crossing is found
+// the impact time is refined by bisection to within tol seconds and phys is left at the impact state.
+// ok is false if no impact was found before tMax.
+func (phys *PhysicsPointIntegrator) FindImpact(terrain Terrain, dt, tMax, tol float64, externalAccelGeographicFrameNoGravity md3.Vec) (impact Impact, ok bool) {
+	if dt <= 0 || tol <= 0 {
+		panic("FindImpact requires positive dt and tol")
+	}
+	t, SBI, VBI := phys.integrator.State()
+	above := phys.heightAboveTerrain(t, SBI, terrain) >= 0
+	for t < tMax {
+		start := phys.checkpoint()
+		t, SBI, VBI = phys.Step(min(dt, tMax-t), externalAccelGeographicFrameNoGravity)
+		nowAbove := phys.heightAboveTerrain(t, SBI, terrain) >= 0
+		if !above || nowAbove {
+			above = nowAbove
+			continue
+		}
+		// Crossing found within [start.t, t]. Bisect to find the impact time.
+		t, SBI, VBI = phys.bisectEvent(start, t-start.t, tol, externalAccelGeographicFrameNoGravity, func(t float64, SBI md3.Vec) bool {
+			return phys.heightAboveTerrain(t, SBI, terrain) < 0
+		})
+		return Impact{
+			T:      t,
+			SBI:    SBI,
+			VBI:    VBI,
+			Coords: phys.geocentric(t, SBI),
+		}, true
+	}
+	return Impact{}, false
+}
+
+// bisectEvent finds the earliest time within a step of length h starting at checkpoint start at
+// which event becomes true to within tol seconds given event is false at start and true at the end of the step.
+// phys is left at the state where the event has just become true. The variational equations are
+// rewound with the state so the state transition matrix does not include the bisection's trial steps.
+func (phys *PhysicsPointIntegrator) bisectEvent(start checkpoint, h, tol float64, extAccel md3.Vec, event func(t float64, SBI md3.Vec) bool) (t float64, SBI, VBI md3.Vec) {
+	lo, hi := 0.0, h
+	for hi-lo > tol {
+		mid := (lo + hi) / 2
+		phys.rewind(start)
+		tm, SBIm, _ := phys.Step(mid, extAccel)
+		if event(tm, SBIm) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	phys.rewind(start)
+	return phys.Step(hi, extAccel)
+}
+
+// geocentric returns the geocentric coordinates of inertial position SBI at epoch time t.
+func (phys *PhysicsPointIntegrator) geocentric(t float64, SBI md3.Vec) GeocentricCoords {
+	w := phys.coord.World()
+	return w.GeocentricFromEarthFixedCoords(md3.MulMatVec(w.TEI(t), SBI))
+}
+
+func (phys *PhysicsPointIntegrator) heightAboveTerrain(t float64, SBI md3.Vec, terrain Terrain) float64 {
+	return phys.geocentric(t, SBI).HeightAboveTerrain(terrain)
+}
@@ -0,0 +1,94 @@
+package gnco
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestSRTMTileOrigin(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		south, west float64
+		wantErr     bool
+	}{
+		{name: "N34W119.hgt", south: 34, west: -119},
+		{name: "/data/s35e058.HGT", south: -35, west: 58},
+		{name: "X34W119.hgt", wantErr: true},
+		{name: "N34W11.hgt", wantErr: true},
+	} {
+		south, west, err := srtmTileOrigin(test.name)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: unexpected error result %v", test.name, err)
+		} else if south != test.south || west != test.west {
+			t.Errorf("%s: want (%g,%g), got (%g,%g)", test.name, test.south, test.west, south, west)
+		}
+	}
+}
+
+func TestLoadRawTerrainGrid(t *testing.T) {
+	const void = -32768
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []int16{
+		100, 200,
+		void, 400,
+	})
+	terrain, err := LoadRawTerrainGrid(&buf, binary.BigEndian, void, 1, 0, -1, 1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const deg = math.Pi / 180
+	for _, test := range []struct{ lat, long, want float64 }{
+		{lat: 1 * deg, long: 0, want: 100},
+		{lat: 1 * deg, long: 1 * deg, want: 200},
+		{lat: 0, long: 0, want: 0},
+		{lat: 0.5 * deg, long: 0.5 * deg, want: 175},
+		{lat: 5 * deg, long: 5 * deg, want: 200}, // Clamped to edge.
+	} {
+		got := terrain.Height(test.lat, test.long)
+		if !md1.EqualWithinAbs(got, test.want, 1e-9) {
+			t.Errorf("(%g,%g): want %g, got %g", test.lat/deg, test.long/deg, test.want, got)
+		}
+	}
+}
+
+func TestFindImpact(t *testing.T) {
+	const (
+		dropHeight = 100.
+		terrainH   = 20.
+		deg        = math.Pi / 180
+	)
+	earth := NewEarth()
+	start := earth.GeocentricFromHASL(-34.6*deg, -58.4*deg, dropHeight+terrainH)
+	SBI0, TGI := start.InertialCoords(0)
+	// Dropped from rest relative to the rotating Earth.
+	omega := md3.Vec{Z: earth.Rotation}
+	VBI0 := md3.Cross(omega, SBI0)
+	coords := start
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	impact, ok := phys.FindImpact(ConstantTerrain(terrainH), 0.1, 60, 1e-6, md3.Vec{})
+	if !ok {
+		t.Fatal("impact not found")
+	}
+	// Apparent gravity includes the centrifugal acceleration of the rotating frame.
+	centrifugalG := md3.MulMatVec(TGI, md3.Scale(-1, md3.Cross(omega, VBI0)))
+	g := start.AGravG().Z + centrifugalG.Z
+	wantT := math.Sqrt(2 * dropHeight / g)
+	if !md1.EqualWithinAbs(impact.T, wantT, 1e-3) {
+		t.Errorf("impact time: want %g, got %g", wantT, impact.T)
+	}
+	if h := impact.Coords.HeightAboveTerrain(ConstantTerrain(terrainH)); !md1.EqualWithinAbs(h, 0, 1e-3) {
+		t.Errorf("impact height above terrain: want 0, got %g", h)
+	}
+	if tp, _, _ := phys.integrator.State(); tp != impact.T {
+		t.Errorf("integrator not left at impact state: %g != %g", tp, impact.T)
+	}
+	_, ok = phys.FindImpact(ConstantTerrain(terrainH), 0.1, impact.T+10, 1e-6, md3.Vec{})
+	if ok {
+		t.Error("impact found while starting below terrain")
+	}
+}
@@ -0,0 +1,141 @@
+package gnco
+
+import (
+	"math"
+
+	"github.com/soypat/geometry/md3"
+)
+
+// Gravitational parameters of common perturbing bodies [m^3.s^-2].
+const (
+	GMSun  = 1.32712440018e20
+	GMMoon = 4.9028001e12
+)
+
+const (
+	// JulianDateJ2000 is the Julian date of the J2000 epoch, 2000 January 1 12:00 TT.
+	JulianDateJ2000 = 2451545.0
+	// obliquityJ2000 is the obliquity of the ecliptic at J2000 [rad].
+	obliquityJ2000 = 23.43929111 * math.Pi / 180
+	secondsPerDay  = 86400.
+	arcsec         = math.Pi / (180 * 3600)
+	deg            = math.Pi / 180
+)
+
+// SunPositionJ2000 returns the low precision geocentric position of the Sun referred to the
+// mean equator and equinox of J2000 [m] at the given Julian date. Accuracy is around 0.1%
+// which is sufficient for perturbation modelling. See Montenbruck, Gill - Satellite Orbits (3.3.2).
+func SunPositionJ2000(jd float64) md3.Vec {
+	T := (jd - JulianDateJ2000) / 36525
+	M := (357.5256 + 35999.049*T) * deg // Mean anomaly.
+	sinM, cosM := math.Sincos(M)
+	sin2M, cos2M := math.Sincos(2 * M)
+	// Ecliptic longitude and distance.
+	lambda := 282.94*deg + M + 6892*arcsec*sinM + 72*arcsec*sin2M
+	r := (149.619 - 2.499*cosM - 0.021*cos2M) * 1e9
+	sinl, cosl := math.Sincos(lambda)
+	return eclipticToEquatorial(md3.Vec{X: r * cosl, Y: r * sinl})
+}
+
+// MoonPositionJ2000 returns the low precision geocentric position of the Moon referred to the
+// mean equator and equinox of J2000 [m] at the given Julian date. Accuracy is several arcminutes
+// in angle and around 500km in distance. See Montenbruck, Gill - Satellite Orbits (3.3.2).
+func MoonPositionJ2000(jd float64) md3.Vec {
+	T := (jd - JulianDateJ2000) / 36525
+	L0 := (218.31617 + 481267.88088*T - 1.3972*T) * deg // Mean longitude.
+	l := (134.96292 + 477198.86753*T) * deg             // Moon mean anomaly.
+	lp := (357.52543 + 35999.04944*T) * deg             // Sun mean anomaly.
+	F := (93.27283 + 483202.01873*T) * deg              // Mean argument of latitude.
+	D := (297.85027 + 445267.11135*T) * deg             // Mean elongation from the Sun.
+	lambda := L0 + arcsec*(22640*math.Sin(l)+769*math.Sin(2*l)-
+		4586*math.Sin(l-2*D)+2370*math.Sin(2*D)-
+		668*math.Sin(lp)-412*math.Sin(2*F)-
+		212*math.Sin(2*l-2*D)-206*math.Sin(l+lp-2*D)+
+		192*math.Sin(l+2*D)-165*math.Sin(lp-2*D)+
+		148*math.Sin(l-lp)-125*math.Sin(D)-
+		110*math.Sin(l+lp)-55*math.Sin(2*F-2*D))
+	beta := arcsec * (18520*math.Sin(F+lambda-L0+arcsec*(412*math.Sin(2*F)+541*math.Sin(lp))) -
+		526*math.Sin(F-2*D) + 44*math.Sin(l+F-2*D) - 31*math.Sin(-l+F-2*D) -
+		25*math.Sin(-2*l+F) - 23*math.Sin(lp+F-2*D) + 21*math.Sin(-l+F) + 11*math.Sin(-lp+F-2*D))
+	r := 1e3 * (385000 - 20905*math.Cos(l) - 3699*math.Cos(2*D-l) - 2956*math.Cos(2*D) -
+		570*math.Cos(2*l) + 246*math.Cos(2*l-2*D) - 205*math.Cos(lp-2*D) -
+		171*math.Cos(l+2*D) - 152*math.Cos(l+lp-2*D))
+	sinl, cosl := math.Sincos(lambda)
+	sinb, cosb := math.Sincos(beta)
+	return eclipticToEquatorial(md3.Vec{X: r * cosl * cosb, Y: r * sinl * cosb, Z: r * sinb})
+}
+
+// GreenwichMeanSiderealTime returns the angle between the vernal equinox and the
+// Greenwich meridian [rad] in range [0, 2pi) at the given Julian date.
+func GreenwichMeanSiderealTime(jd float64) float64 {
+	d := jd - JulianDateJ2000
+	T := d / 36525
+	gmst := (280.46061837 + 360.98564736629*d + T*T*(0.000387933-T/38710000)) * deg
+	return wrapBearing(gmst)
+}
+
+func eclipticToEquatorial(ecl md3.Vec) md3.Vec {
+	sine, cose := math.Sincos(obliquityJ2000)
+	return md3.Vec{
+		X: ecl.X,
+		Y: cose*ecl.Y - sine*ecl.Z,
+		Z: sine*ecl.Y + cose*ecl.Z,
+	}
+}
+
+// ThirdBody models the point mass gravitational perturbation of a body other
+// than the central world, such as the Sun or Moon acting on an Earth satellite.
+type ThirdBody struct {
+	// GM is the gravitational parameter of the perturbing body [m^3.s^-2].
+	GM float64
+	// Ephemeris returns the position of the perturbing body relative to the center of
+	// the world referred to the mean equator and equinox of J2000 [m] at a Julian date.
+	Ephemeris func(jd float64) md3.Vec
+	// EpochJD is the Julian date corresponding to epoch time zero of the integrator.
+	EpochJD float64
+	w       *World
+}
+
+// NewSunPerturbation returns the Sun third body perturbation for an integrator whose
+// epoch time zero corresponds to the Julian date epochJD.
+func NewSunPerturbation(w *World, epochJD float64) *ThirdBody {
+	return &ThirdBody{GM: GMSun, Ephemeris: SunPositionJ2000, EpochJD: epochJD, w: w}
+}
+
+// NewMoonPerturbation returns the Moon third body perturbation for an integrator whose
+// epoch time zero corresponds to the Julian date epochJD.
+func NewMoonPerturbation(w *World, epochJD float64) *ThirdBody {
+	return &ThirdBody{GM: GMMoon, Ephemeris: MoonPositionJ2000, EpochJD: epochJD, w: w}
+}
+
+// PositionInertial returns the position of the perturbing body in the world's inertial frame [m]
+// at epoch time t [s]. The world's prime meridian is at its celestial longitude from the inertial
+// X axis at epoch time zero, so J2000 positions are rotated by the sidereal time at EpochJD
+// minus the celestial longitude. The inertial frame is aligned with J2000 when the celestial
+// longitude equals the sidereal time at EpochJD.
+func (tb *ThirdBody) PositionInertial(t float64) md3.Vec {
+	sJ2000 := tb.Ephemeris(tb.EpochJD + t/secondsPerDay)
+	theta := GreenwichMeanSiderealTime(tb.EpochJD) - tb.w.celestialLong
+	s, c := math.Sincos(theta)
+	return md3.Vec{
+		X: c*sJ2000.X + s*sJ2000.Y,
+		Y: -s*sJ2000.X + c*sJ2000.Y,
+		Z: sJ2000.Z,
+	}
+}
+
+// AccelInertial returns the perturbing acceleration in inertial frame [m.s^-2] on a body
+// at inertial position SBI [m] at epoch time t [s]. This is the difference between the
+// attraction of the third body on the object and its attraction on the central world.
+func (tb *ThirdBody) AccelInertial(t float64, SBI md3.Vec) md3.Vec {
+	s := tb.PositionInertial(t)
+	d := md3.Sub(s, SBI)
+	dnorm := md3.Norm(d)
+	snorm := md3.Norm(s)
+	return md3.Scale(tb.GM, md3.Sub(md3.Scale(1/(dnorm*dnorm*dnorm), d), md3.Scale(1/(snorm*snorm*snorm), s)))
+}
+
+// Accel returns the third body perturbation in inertial frame [m.s^-2]. It implements [ForceModel].
+func (tb *ThirdBody) Accel(s *ForceState) md3.Vec {
+	return tb.AccelInertial(s.T, s.SBI)
+}
@@ -0,0 +1,96 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestEphemerides(t *testing.T) {
+	const au = 149597870.7e3
+	// Reference ecliptic coordinates from Meeus - Astronomical Algorithms, examples 25.a and 47.a.
+	// Meeus refers positions to the equinox of date, hence the angle tolerance.
+	for _, test := range []struct {
+		name              string
+		got               md3.Vec
+		wantLong, wantLat float64
+		wantDist, distTol float64
+		angleTol          float64
+	}{
+		{name: "sun", got: SunPositionJ2000(2448908.5), wantLong: 199.90988 * deg, wantDist: 0.99760775 * au, distTol: 1e-3, angleTol: 0.2 * deg},
+		{name: "moon", got: MoonPositionJ2000(2448724.5), wantLong: 133.162655 * deg, wantLat: -3.229126 * deg, wantDist: 368409.7e3, distTol: 2e-3, angleTol: 0.2 * deg},
+	} {
+		// Rotate back to ecliptic coordinates.
+		sine, cose := math.Sincos(obliquityJ2000)
+		ecl := md3.Vec{X: test.got.X, Y: cose*test.got.Y + sine*test.got.Z, Z: -sine*test.got.Y + cose*test.got.Z}
+		dist := md3.Norm(ecl)
+		long := wrapBearing(math.Atan2(ecl.Y, ecl.X))
+		lat := math.Asin(ecl.Z / dist)
+		if !md1.EqualWithinAbs(long, test.wantLong, test.angleTol) || !md1.EqualWithinAbs(lat, test.wantLat, test.angleTol) {
+			t.Errorf("%s: want ecliptic (%g,%g), got (%g,%g) degrees", test.name, test.wantLong/deg, test.wantLat/deg, long/deg, lat/deg)
+		}
+		if !md1.EqualWithinAbs(dist/test.wantDist, 1, test.distTol) {
+			t.Errorf("%s: want distance %g, got %g", test.name, test.wantDist, dist)
+		}
+	}
+	// Sidereal time at J2000 epoch.
+	if gmst := GreenwichMeanSiderealTime(JulianDateJ2000); !md1.EqualWithinAbs(gmst, 280.46061837*deg, 1e-12) {
+		t.Errorf("GMST: got %g", gmst/deg)
+	}
+}
+
+func TestThirdBodyGEO(t *testing.T) {
+	earth := NewEarth()
+	const rGEO = 42164e3
+	sun := NewSunPerturbation(earth, JulianDateJ2000)
+	moon := NewMoonPerturbation(earth, JulianDateJ2000)
+	// Acceleration magnitudes at GEO are well known orders of magnitude.
+	SBI := md3.Vec{X: rGEO}
+	aSun := md3.Norm(sun.AccelInertial(0, SBI))
+	aMoon := md3.Norm(moon.AccelInertial(0, SBI))
+	if aSun < 1e-6 || aSun > 4e-6 {
+		t.Errorf("unexpected sun perturbation at GEO %g", aSun)
+	}
+	if aMoon < 2e-6 || aMoon > 9e-6 {
+		t.Errorf("unexpected moon perturbation at GEO %g", aMoon)
+	}
+	// At the center of the world the perturbation vanishes.
+	if a := md3.Norm(moon.AccelInertial(0, md3.Vec{})); a != 0 {
+		t.Errorf("want no perturbation at world center, got %g", a)
+	}
+
+	// The inertial frame is aligned with J2000 when the prime meridian is at the sidereal time of the epoch.
+	cfg := earth.Config()
+	cfg.CelestialLong = GreenwichMeanSiderealTime(JulianDateJ2000)
+	aligned, err := NewWorld(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := NewMoonPerturbation(aligned, JulianDateJ2000).PositionInertial(3600)
+	if want := MoonPositionJ2000(JulianDateJ2000 + 3600/secondsPerDay); !md3.EqualElem(got, want, 1e-3) {
+		t.Errorf("aligned frame: want moon at %v, got %v", want, got)
+	}
+
+	// Propagate a GEO orbit for a day with and without perturbations.
+	v := math.Sqrt(earth.G() / rGEO)
+	propagate := func(perturbed bool) md3.Vec {
+		coords := earth.GeocentricFromDegrees(0, 0, 0)
+		phys := NewPhysicsPointIntegrator(&coords, 0, SBI, md3.Vec{Y: v})
+		if perturbed {
+			phys.AddForceModel("sun", sun)
+			phys.AddForceModel("moon", moon)
+		}
+		var SBIf md3.Vec
+		for i := 0; i < 24*6; i++ {
+			_, SBIf, _ = phys.Step(600, md3.Vec{})
+		}
+		return SBIf
+	}
+	diff := md3.Norm(md3.Sub(propagate(true), propagate(false)))
+	// Luni-solar perturbations displace GEO satellites by kilometers in a day.
+	if diff < 100 || diff > 50e3 {
+		t.Errorf("unexpected luni-solar displacement after a day: %gm", diff)
+	}
+}
@@ -0,0 +1,290 @@
+package gnco
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+// StagingTrigger selects the condition that triggers separation of a stage.
+type StagingTrigger uint8
+
+const (
+	// StageOnBurnout separates the stage when its engine burns out or its propellant is exhausted.
+	StageOnBurnout StagingTrigger = iota
+	// StageOnTime separates the stage a fixed time after its ignition.
+	StageOnTime
+	// StageOnAltitude separates the stage when the vehicle climbs above a height above the reference ellipsoid.
+	StageOnAltitude
+)
+
+// StagingEvent defines when a stage is separated from the vehicle.
+type StagingEvent struct {
+	Trigger StagingTrigger
+	// Time since ignition of the stage at which it is separated for StageOnTime [s].
+	Time float64
+	// Altitude is the height above reference ellipsoid at which the stage is separated for StageOnAltitude [m].
+	Altitude float64
+}
+
+// Stage is a single stage of a multi-stage [Vehicle].
+type Stage struct {
+	Name           string
+	DryMass        float64 // Mass of the stage without propellant [kg].
+	PropellantMass float64 // [kg]
+	Engine         Engine
+	// IgnitionDelay is the coast time between separation of the previous stage and ignition of this stage [s].
+	IgnitionDelay float64
+	// Aerodynamic data of the vehicle while this stage is the bottom stage. Also used for
+	// the stage alone after separation. A zero area disables drag.
+	DragCoefficient float64 // [Adim]
+	Area            float64 // Reference area [m^2].
+	Separation      StagingEvent
+}
+
+// burnTime returns the time since ignition at which the stage's engine burns out or its propellant is exhausted.
+func (st *Stage) burnTime() float64 {
+	tb := st.Engine.Curve.BurnTime()
+	if st.Engine.PropellantConsumed(tb) <= st.PropellantMass {
+		return tb
+	}
+	// Propellant consumed is monotonic with time.
+	lo, hi := 0.0, tb
+	for i := 0; i < 64 && hi-lo > 1e-9; i++ {
+		mid := (lo + hi) / 2
+		if st.Engine.PropellantConsumed(mid) < st.PropellantMass {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// Vehicle is a multi-stage rocket force model. The bottom stage is active and
+// stages are separated in order by a [Flight] as their staging events occur.
+type Vehicle struct {
+	// Stages ordered from bottom (first to burn) to top.
+	Stages      []Stage
+	PayloadMass float64 // [kg]
+	// Atmosphere used for drag. If nil drag is not modelled.
+	Atmosphere Atmosphere
+	// Direction returns the thrust direction in inertial frame. See [Propulsion.Direction].
+	Direction func(s *ForceState) md3.Vec
+
+	active  int
+	burnout float64 // Epoch time of active stage burnout or propellant exhaustion.
+	prop    Propulsion
+	// When sequenced by a Flight the engine state is held constant over a step
+	// so thrust discontinuities at ignition and burnout fall on step boundaries.
+	sequenced, engineOn bool
+}
+
+var _ ForceModel = (*Vehicle)(nil)
+
+// NewVehicle returns a vehicle whose bottom stage ignites at epoch time ignitionTime [s].
+func NewVehicle(stages []Stage, payloadMass, ignitionTime float64) (*Vehicle, error) {
+	if len(stages) == 0 {
+		return nil, errors.New("vehicle requires at least one stage")
+	} else if payloadMass < 0 {
+		return nil, errors.New("negative payload mass")
+	}
+	for i := range stages {
+		st := &stages[i]
+		switch {
+		case !(st.DryMass > 0) || st.PropellantMass < 0:
+			return nil, fmt.Errorf("stage %d: dry mass must be positive and propellant mass non-negative", i)
+		case st.Engine.Curve == nil || !(st.Engine.Isp > 0):
+			return nil, fmt.Errorf("stage %d: engine requires thrust curve and positive Isp", i)
+		case st.IgnitionDelay < 0:
+			return nil, fmt.Errorf("stage %d: negative ignition delay", i)
+		case st.Separation.Trigger > StageOnAltitude:
+			return nil, fmt.Errorf("stage %d: unknown staging trigger", i)
+		}
+	}
+	v := &Vehicle{Stages: stages, PayloadMass: payloadMass}
+	v.ignite(0, ignitionTime)
+	return v, nil
+}
+
+// ActiveStage returns the index of the active (bottom) stage. It returns len(v.Stages) once all stages are separated.
+func (v *Vehicle) ActiveStage() int { return v.active }
+
+// IgnitionTime returns the epoch time at which the active stage ignites [s].
+func (v *Vehicle) IgnitionTime() float64 { return v.prop.IgnitionTime }
+
+// Burnout returns the epoch time at which the active stage burns out or exhausts its propellant [s].
+func (v *Vehicle) Burnout() float64 { return v.burnout }
+
+// Cutoff commands the active stage's engine to shut down at epoch time t [s], such as on reaching
+// a guidance target. It has no effect if the engine would burn out before t.
+func (v *Vehicle) Cutoff(t float64) {
+	if v.active < len(v.Stages) {
+		v.burnout = math.Max(math.Min(v.burnout, t), v.prop.IgnitionTime)
+	}
+}
+
+// Mass returns the mass of the vehicle at epoch time t [kg].
+func (v *Vehicle) Mass(t float64) float64 {
+	if v.active >= len(v.Stages) {
+		return v.PayloadMass
+	}
+	return v.prop.Mass(math.Min(t, v.burnout))
+}
+
+// Accel returns the thrust and drag acceleration of the vehicle in inertial frame [m.s^-2]. It implements [ForceModel].
+func (v *Vehicle) Accel(s *ForceState) md3.Vec {
+	if v.active >= len(v.Stages) {
+		return md3.Vec{}
+	}
+	var a md3.Vec
+	on := s.T >= v.prop.IgnitionTime && s.T < v.burnout
+	if v.sequenced {
+		on = v.engineOn
+	}
+	if on {
+		// Thrust is evaluated within the burn so step boundaries at ignition and burnout see the engine burning.
+		burning := *s
+		burning.T = md1.Clamp(s.T, v.prop.IgnitionTime, math.Nextafter(v.burnout, math.Inf(-1)))
+		a = v.prop.accel(s, v.prop.Thrust(&burning), v.Direction)
+	}
+	st := &v.Stages[v.active]
+	if v.Atmosphere != nil && st.Area > 0 {
+		drag := Drag{BallisticCoefficient: v.Mass(s.T) / (st.DragCoefficient * st.Area), Atmosphere: v.Atmosphere}
+		a = md3.Add(a, drag.Accel(s))
+	}
+	return a
+}
+
+// ignite makes stage i the active stage with ignition after its delay from epoch time t.
+func (v *Vehicle) ignite(i int, t float64) {
+	v.active = i
+	if i >= len(v.Stages) {
+		return
+	}
+	mass := v.PayloadMass
+	for j := i; j < len(v.Stages); j++ {
+		mass += v.Stages[j].DryMass + v.Stages[j].PropellantMass
+	}
+	st := &v.Stages[i]
+	v.prop = Propulsion{
+		Engine:       st.Engine,
+		IgnitionTime: t + st.IgnitionDelay,
+		InitialMass:  mass,
+	}
+	v.burnout = v.prop.IgnitionTime + st.burnTime()
+}
+
+// separationTime returns the epoch time of the active stage's separation. It returns +Inf for altitude events.
+func (v *Vehicle) separationTime() float64 {
+	ev := v.Stages[v.active].Separation
+	switch ev.Trigger {
+	case StageOnBurnout:
+		return v.burnout
+	case StageOnTime:
+		return v.prop.IgnitionTime + ev.Time
+	}
+	return math.Inf(1)
+}
+
+// Separation records the state of a stage at the moment it was jettisoned.
+type Separation struct {
+	Stage int     // Index of the separated stage in the vehicle.
+	T     float64 // Epoch time of separation [s].
+	SBI   md3.Vec // Inertial position at separation [m].
+	VBI   md3.Vec // Inertial velocity at separation [m/s].
+	// Mass of the jettisoned stage including unburnt propellant [kg].
+	Mass float64
+}
+
+// Flight sequences the stages of a [Vehicle] flying under a [PhysicsPointIntegrator],
+// separating stages exactly at their staging events.
+type Flight struct {
+	Vehicle *Vehicle
+	Phys    *PhysicsPointIntegrator
+	// EventTolerance is the time tolerance with which altitude staging events are located [s].
+	EventTolerance float64
+	// Separations are the stages jettisoned so far in order of separation.
+	Separations []Separation
+}
+
+// NewFlight registers v as the force model "vehicle" on phys and returns the flight sequencer.
+// Drag and thrust depend on velocity so phys should use [MethodGRKN54].
+func NewFlight(v *Vehicle, phys *PhysicsPointIntegrator) *Flight {
+	phys.AddForceModel("vehicle", v)
+	return &Flight{Vehicle: v, Phys: phys, EventTolerance: 1e-6}
+}
+
+// Step advances the flight by dt seconds, separating stages whose staging event occurs within the step.
+// The step is split at ignition, burnout and staging events so the trajectory continues with the remaining stack.
+func (f *Flight) Step(dt float64) (t float64, SBI, VBI md3.Vec) {
+	v := f.Vehicle
+	t, SBI, VBI = f.Phys.State()
+	tEnd := t + dt
+	v.sequenced = true
+	defer func() { v.sequenced = false }()
+	for t < tEnd {
+		if v.active >= len(v.Stages) {
+			return f.Phys.Step(tEnd-t, md3.Vec{})
+		}
+		ev := v.Stages[v.active].Separation
+		tSep := v.separationTime()
+		tNext := math.Min(tEnd, tSep)
+		for _, tEvent := range [2]float64{v.prop.IgnitionTime, v.burnout} {
+			if tEvent > t {
+				tNext = math.Min(tNext, tEvent)
+			}
+		}
+		separate := tSep <= tNext
+		v.engineOn = t >= v.prop.IgnitionTime && t < v.burnout
+		if h := tNext - t; h > 0 {
+			start := f.Phys.checkpoint()
+			t, SBI, VBI = f.Phys.Step(h, md3.Vec{})
+			if tNext != tEnd {
+				// Avoid accumulating round-off at event times.
+				t = tNext
+				f.Phys.integrator.SetState(t, SBI, VBI)
+			}
+			if ev.Trigger == StageOnAltitude {
+				separate = f.Phys.heightAboveEllipsoid(t, SBI) >= ev.Altitude
+				if separate && f.Phys.heightAboveEllipsoid(start.t, start.SBI) < ev.Altitude {
+					t, SBI, VBI = f.Phys.bisectEvent(start, h, f.EventTolerance, md3.Vec{}, func(t float64, SBI md3.Vec) bool {
+						return f.Phys.heightAboveEllipsoid(t, SBI) >= ev.Altitude
+					})
+				}
+			}
+		}
+		if !separate {
+			continue
+		}
+		st := &v.Stages[v.active]
+		stageMass := st.DryMass + st.PropellantMass - v.prop.Engine.PropellantConsumed(math.Min(t, v.burnout)-v.prop.IgnitionTime)
+		f.Separations = append(f.Separations, Separation{Stage: v.active, T: t, SBI: SBI, VBI: VBI, Mass: stageMass})
+		v.ignite(v.active+1, t)
+	}
+	return t, SBI, VBI
+}
+
+// ImpactZone propagates a jettisoned stage on a separate [PhysicsPointIntegrator] from its separation
+// state under central gravity and drag until it impacts terrain. See [PhysicsPointIntegrator.FindImpact]
+// for dt, tMax and tol. ok is false if the stage does not impact before epoch time tMax.
+func (f *Flight) ImpactZone(sep Separation, terrain Terrain, dt, tMax, tol float64) (impact Impact, ok bool) {
+	coords := f.Phys.geocentric(sep.T, sep.SBI)
+	phys := NewPhysicsPointIntegrator(&coords, sep.T, sep.SBI, sep.VBI)
+	phys.SetMethod(MethodGRKN54)
+	st := &f.Vehicle.Stages[sep.Stage]
+	if f.Vehicle.Atmosphere != nil && st.Area > 0 {
+		phys.AddForceModel("drag", NewDrag(f.Vehicle.Atmosphere, sep.Mass, st.DragCoefficient, st.Area))
+	}
+	return phys.FindImpact(terrain, dt, tMax, tol, md3.Vec{})
+}
+
+// heightAboveEllipsoid returns the height above the reference ellipsoid of inertial position SBI at epoch time t.
+func (phys *PhysicsPointIntegrator) heightAboveEllipsoid(t float64, SBI md3.Vec) float64 {
+	w := phys.coord.World()
+	_, _, h := w.GeodeticFromEarthFixed(md3.MulMatVec(w.TEI(t), SBI))
+	return h
+}
@@ -0,0 +1,147 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestFlightStaging(t *testing.T) {
+	// Two stage vehicle in free space. Velocity gained is the sum of each stage's rocket equation.
+	const isp1, isp2, payload = 280., 320., 100.
+	stages := []Stage{
+		{
+			DryMass: 200, PropellantMass: 800,
+			// Engine would burn longer than propellant lasts.
+			Engine:     Engine{Curve: ConstantThrust{Thrust: 30e3, Duration: 200}, Isp: isp1},
+			Separation: StagingEvent{Trigger: StageOnBurnout},
+		},
+		{
+			DryMass: 50, PropellantMass: 150, IgnitionDelay: 5,
+			Engine:     Engine{Curve: ConstantThrust{Thrust: 3e3, Duration: 200}, Isp: isp2},
+			Separation: StagingEvent{Trigger: StageOnTime, Time: 100},
+		},
+	}
+	v, err := NewVehicle(stages, payload, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Direction = func(s *ForceState) md3.Vec { return md3.Vec{Z: 1} }
+	earth := NewEarth()
+	coords := earth.GeocentricFromDegrees(0, 0, 0)
+	phys := NewPhysicsPointIntegrator(&coords, 0, md3.Vec{X: earth.SemiMajorAxis + 1000e3}, md3.Vec{})
+	phys.RemoveForceModel("gravity")
+	phys.SetMethod(MethodGRKN54)
+	flight := NewFlight(v, phys)
+	burn1 := 800 * isp1 * StandardGravity / 30e3
+	if !md1.EqualWithinAbs(v.Burnout(), 10+burn1, 1e-6) {
+		t.Errorf("want first stage propellant exhaustion at %g, got %g", 10+burn1, v.Burnout())
+	}
+	var VBI md3.Vec
+	for i := 0; i < 300; i++ {
+		_, _, VBI = flight.Step(1)
+	}
+	if v.ActiveStage() != 2 || len(flight.Separations) != 2 {
+		t.Fatalf("want both stages separated, got active=%d separations=%d", v.ActiveStage(), len(flight.Separations))
+	}
+	sep1, sep2 := flight.Separations[0], flight.Separations[1]
+	if !md1.EqualWithinAbs(sep1.T, 10+burn1, 1e-6) || !md1.EqualWithinAbs(sep1.Mass, 200, 1e-6) {
+		t.Errorf("first separation want t=%g mass=200, got t=%g mass=%g", 10+burn1, sep1.T, sep1.Mass)
+	}
+	// Second stage ignites after delay and separates 100s after ignition with unburnt propellant.
+	m2Burnt := 100 * 3e3 / (isp2 * StandardGravity)
+	if !md1.EqualWithinAbs(sep2.T, 10+burn1+5+100, 1e-6) || !md1.EqualWithinAbs(sep2.Mass, 200-m2Burnt, 1e-9) {
+		t.Errorf("second separation want t=%g mass=%g, got t=%g mass=%g", 10+burn1+105, 200-m2Burnt, sep2.T, sep2.Mass)
+	}
+	if v.Mass(1000) != payload {
+		t.Errorf("want payload mass after separations, got %g", v.Mass(1000))
+	}
+	dv1 := isp1 * StandardGravity * math.Log(1300./500)
+	dv2 := isp2 * StandardGravity * math.Log(300./(300-m2Burnt))
+	if !md1.EqualWithinAbs(VBI.Z, dv1+dv2, 1e-6) || math.Hypot(VBI.X, VBI.Y) > 1e-9 {
+		t.Errorf("want velocity %g, got %v", dv1+dv2, VBI)
+	}
+	if !md1.EqualWithinAbs(md3.Norm(sep1.VBI), dv1, 1e-6) {
+		t.Errorf("want first separation velocity %g, got %g", dv1, md3.Norm(sep1.VBI))
+	}
+}
+
+func TestFlightAltitudeStaging(t *testing.T) {
+	// Sounding rocket launched vertically separates its booster at altitude.
+	const sepAltitude = 5e3
+	stages := []Stage{
+		{
+			DryMass: 100, PropellantMass: 400, DragCoefficient: 0.5, Area: 0.2,
+			Engine:     Engine{Curve: ConstantThrust{Thrust: 40e3, Duration: 30}, Isp: 250, ExitArea: 0.05},
+			Separation: StagingEvent{Trigger: StageOnAltitude, Altitude: sepAltitude},
+		},
+		{
+			DryMass: 50, PropellantMass: 50,
+			Engine:     Engine{Curve: ConstantThrust{Thrust: 5e3, Duration: 20}, Isp: 280},
+			Separation: StagingEvent{Trigger: StageOnBurnout},
+		},
+	}
+	v, err := NewVehicle(stages, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Atmosphere = StandardAtmosphere{}
+	earth := NewEarth()
+	launch := earth.GeocentricFromGeodetic(0, 0, 0)
+	SBI0, _ := launch.InertialCoords(0)
+	VBI0 := md3.Cross(md3.Vec{Z: earth.Rotation}, SBI0)
+	coords := launch
+	phys := NewPhysicsPointIntegrator(&coords, 0, SBI0, VBI0)
+	phys.SetMethod(MethodGRKN54)
+	flight := NewFlight(v, phys)
+	for i := 0; i < 60 && len(flight.Separations) == 0; i++ {
+		flight.Step(0.5)
+	}
+	if len(flight.Separations) != 1 {
+		t.Fatal("booster did not separate")
+	}
+	sep := flight.Separations[0]
+	if h := phys.heightAboveEllipsoid(sep.T, sep.SBI); !md1.EqualWithinAbs(h, sepAltitude, 0.1) {
+		t.Errorf("want separation at %gm, got %gm", sepAltitude, h)
+	}
+	if v.ActiveStage() != 1 {
+		t.Errorf("want second stage active, got %d", v.ActiveStage())
+	}
+	impact, ok := flight.ImpactZone(sep, ConstantTerrain(0), 1, sep.T+600, 1e-3)
+	if !ok {
+		t.Fatal("jettisoned booster did not impact")
+	}
+	if impact.T <= sep.T {
+		t.Errorf("impact before separation: %g <= %g", impact.T, sep.T)
+	}
+	// The booster falls back near the launch site.
+	if d := md3.Norm(md3.Sub(impact.Coords.EarthFixedCoords(), launch.EarthFixedCoords())); d > 5e3 {
+		t.Errorf("impact %gm from launch site", d)
+	}
+}
+
+func TestVehicleDirection(t *testing.T) {
+	v, err := NewVehicle([]Stage{{
+		DryMass: 100, PropellantMass: 100,
+		Engine: Engine{Curve: ConstantThrust{Thrust: 2e3, Duration: 10}, Isp: 300},
+	}}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coords := NewEarth().GeocentricFromDegrees(0, 0, 0)
+	SBI, VBI := md3.Vec{X: 7000e3}, md3.Vec{Y: 7500}
+	s := NewPhysicsPointIntegrator(&coords, 1, SBI, VBI).forceState(1, SBI, VBI)
+	for _, dir := range []md3.Vec{{Z: 1}, {Y: -1}} {
+		v.Direction = func(*ForceState) md3.Vec { return dir }
+		a := v.Accel(&s)
+		if !md3.EqualElem(md3.Unit(a), dir, 1e-12) {
+			t.Errorf("want thrust along %v, got %v", dir, a)
+		}
+	}
+	// Evaluating forces must not modify the vehicle's propulsion.
+	if v.prop.Direction != nil {
+		t.Error("vehicle Accel modified propulsion direction")
+	}
+}
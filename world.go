@@ -15,7 +15,7 @@ func NewEarth() *World {
 		Rotation:       7.292114999999999893e-05,
 		Radius:         6370987.,
 		seaLevelRadius: 6371146,
-		flattening:     3.33528106e-3,
+		flattening:     1 / 298.257223563,
 		celestialLong:  0,
 
 		// SGP4 according to WGS84. Recommended by IAU to propagate orbits
@@ -40,7 +40,10 @@ type World struct {
 	Radius         float64 // Radius of planet [m]
 	seaLevelRadius float64 // If earth stopped rotating the sea level would take this distance from center of earth [m] https://www.esri.com/news/arcuser/0703/geoid3of3.html
 	flattening     float64 // Flattening of planet, (WGS84) [Adim]
-	celestialLong  float64 // Celestial longitude, for earth is Greenwich meridian. Will indicate start of epoch [rad]
+	celestialLong  float64 // Celestial longitude of prime meridian w.r.t inertial X axis at start of epoch, for earth is Greenwich meridian [rad]
+	// Geoid models mean sea level for orthometric height conversions. See [World.GeocentricFromHASL].
+	// If nil mean sea level is taken as the reference ellipsoid.
+	Geoid Geoid
 
 	// SGP4 parameters:
 
@@ -54,12 +57,14 @@ type World struct {
 	J4 float64
 }
 
-func (w *World) GeocentricFromEarthFixedCoords(sBIE md3.Vec, epochTime float64) GeocentricCoords {
+// GeocentricFromEarthFixedCoords returns the geocentric coordinates of a point given
+// its planet-centered, planet-fixed (ECEF) coordinates [m].
+func (w *World) GeocentricFromEarthFixedCoords(sBIE md3.Vec) GeocentricCoords {
 	dbi := md3.Norm(sBIE)
 	lat := math.Asin(sBIE.Z / dbi)
 	elev := dbi - w.Radius
-	// longitude calculation using specialized quadrant algorithm and total earth rotation.
-	long := asinlong(sBIE.Y, sBIE.X) - w.Rotation*epochTime + w.celestialLong
+	// longitude calculation using specialized quadrant algorithm.
+	long := asinlong(sBIE.Y, sBIE.X)
 	long = clampLongLat(long)
 	return GeocentricCoords{
 		w:    w,
@@ -91,6 +96,9 @@ func (w *World) seaLevelHeight() float64 {
 	return w.seaLevelRadius - w.Radius
 }
 
+// HASLToElevation converts a height above sea level to an elevation above the reference sphere
+// using a single mean sea level radius. This approximation can be off by tens of meters
+// depending on location, use [World.GeocentricFromHASL] for accurate conversions.
 func (w *World) HASLToElevation(hasl float64) float64 {
 	if w.seaLevelRadius == 0 {
 		return hasl
@@ -99,9 +107,10 @@ func (w *World) HASLToElevation(hasl float64) float64 {
 }
 
 // TEI returns the [T]^{EI} transformation tensor given the epochTime in seconds.
+// The planet-fixed frame rotates about the inertial Z axis at w.Rotation and its prime
+// meridian is at the world's celestial longitude from the inertial X axis at epoch time zero.
 func (w *World) TEI(epochTime float64) md3.Mat3 {
-	daysPerSec := 1. / w.Day()
-	sin, cos := math.Sincos(epochTime * daysPerSec)
+	sin, cos := math.Sincos(w.Rotation*epochTime + w.celestialLong)
 	return mat3(
 		cos, sin, 0,
 		-sin, cos, 0,
@@ -114,16 +123,16 @@ func (w *World) Day() float64 {
 	return 2 * math.Pi / w.Rotation
 }
 
-// GeographicFromElevBearing returns a vector in geographic coordinates
+// GeographicVectorFromElevationAndBearing returns a vector in geographic coordinates
 // pointing in direction given by an elevation and bearing in radians.
 // When obtaining Geographic coordinates bearing can be thought of as North/West/East/South
 // parameter, while the elevation describes whether direction is Up or Down, with
 //
-// Geographic coordinates:
+// Geographic coordinates (North-East-Down, see [GeocentricCoords.TGE]):
 //
 //	X: North
-//	Y: East // <- TODO this looks wrong...
-//	Z: Center of earth
+//	Y: East
+//	Z: Down, towards center of earth
 //
 // Elevation:
 //
@@ -136,15 +145,48 @@ func (w *World) Day() float64 {
 //	0: Pointing North.
 //	Pi/2: Pointing East.
 //	Pi: Pointing South.
+//
+// The inverse operation is [ElevationAndBearingFromGeographicVector].
 func GeographicVectorFromElevationAndBearing(elevation, bearing, NormOfVector float64) (dirG md3.Vec) {
 	// See CADAC matcar routine.
 	sine, cose := math.Sincos(elevation)
 	sinb, cosb := math.Sincos(bearing)
 	dirG = md3.Vec{
 		X: cosb * cose,
-		Y: -sinb * cose,
+		Y: sinb * cose,
 		Z: -sine,
 	}
-	dirG = md3.Scale(NormOfVector, md3.Unit(dirG)) // TODO: does this need to be normalized before scaling?
-	return dirG
+	return md3.Scale(NormOfVector, dirG) // dirG is already a unit vector.
+}
+
+// ElevationAndBearingFromGeographicVector is the inverse of [GeographicVectorFromElevationAndBearing].
+// It returns the elevation in range [-pi/2, pi/2], the bearing in range [0, 2pi) and the norm of dirG.
+// A zero vector returns all zero values.
+func ElevationAndBearingFromGeographicVector(dirG md3.Vec) (elevation, bearing, norm float64) {
+	norm = md3.Norm(dirG)
+	if norm == 0 {
+		return 0, 0, 0
+	}
+	horizontal := math.Hypot(dirG.X, dirG.Y)
+	elevation = math.Atan2(-dirG.Z, horizontal)
+	if horizontal == 0 {
+		return elevation, 0, norm // Bearing undefined when pointing straight up or down.
+	}
+	bearing = math.Atan2(dirG.Y, dirG.X)
+	if bearing < 0 {
+		bearing += 2 * math.Pi
+	}
+	return elevation, bearing, norm
+}
+
+// GeographicToENU converts a vector in geographic North-East-Down coordinates
+// to East-North-Up coordinates.
+func GeographicToENU(vG md3.Vec) (vENU md3.Vec) {
+	return md3.Vec{X: vG.Y, Y: vG.X, Z: -vG.Z}
+}
+
+// ENUToGeographic converts a vector in East-North-Up coordinates
+// to geographic North-East-Down coordinates.
+func ENUToGeographic(vENU md3.Vec) (vG md3.Vec) {
+	return md3.Vec{X: vENU.Y, Y: vENU.X, Z: -vENU.Z}
 }
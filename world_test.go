@@ -0,0 +1,141 @@
+package gnco
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soypat/geometry/md1"
+	"github.com/soypat/geometry/md3"
+)
+
+func TestGeographicVectorFromElevationAndBearing(t *testing.T) {
+	const tol = 1e-12
+	for _, test := range []struct {
+		elevation, bearing float64
+		want               md3.Vec
+	}{
+		{elevation: 0, bearing: 0, want: md3.Vec{X: 1}},                // North.
+		{elevation: 0, bearing: math.Pi / 2, want: md3.Vec{Y: 1}},      // East.
+		{elevation: 0, bearing: math.Pi, want: md3.Vec{X: -1}},         // South.
+		{elevation: 0, bearing: 3 * math.Pi / 2, want: md3.Vec{Y: -1}}, // West.
+		{elevation: math.Pi / 2, bearing: 0, want: md3.Vec{Z: -1}},     // Up.
+		{elevation: -math.Pi / 2, bearing: 0, want: md3.Vec{Z: 1}},     // Down.
+	} {
+		got := GeographicVectorFromElevationAndBearing(test.elevation, test.bearing, 2)
+		if !md3.EqualElem(got, md3.Scale(2, test.want), tol) {
+			t.Errorf("elev=%g bearing=%g: want %v, got %v", test.elevation, test.bearing, md3.Scale(2, test.want), got)
+		}
+		gotElev, gotBearing, gotNorm := ElevationAndBearingFromGeographicVector(got)
+		if !md1.EqualWithinAbs(gotElev, test.elevation, tol) || !md1.EqualWithinAbs(gotBearing, test.bearing, tol) || !md1.EqualWithinAbs(gotNorm, 2, tol) {
+			t.Errorf("inverse: want (%g,%g,2), got (%g,%g,%g)", test.elevation, test.bearing, gotElev, gotBearing, gotNorm)
+		}
+		enu := GeographicToENU(got)
+		if !md3.EqualElem(ENUToGeographic(enu), got, tol) {
+			t.Errorf("ENU round trip failed for %v", got)
+		}
+	}
+}
+
+func TestGeographicFrameAxes(t *testing.T) {
+	// At latitude=0, longitude=0 the geographic frame is North-East-Down.
+	earth := NewEarth()
+	g := earth.GeocentricFromDegrees(0, 0, 0)
+	TGE := g.TGE()
+	for _, test := range []struct {
+		vG, wantE md3.Vec
+	}{
+		{vG: md3.Vec{X: 1}, wantE: md3.Vec{Z: 1}},  // North.
+		{vG: md3.Vec{Y: 1}, wantE: md3.Vec{Y: 1}},  // East.
+		{vG: md3.Vec{Z: 1}, wantE: md3.Vec{X: -1}}, // Down.
+	} {
+		got := md3.MulMatVecTrans(TGE, test.vG)
+		if !md3.EqualElem(got, test.wantE, 1e-12) {
+			t.Errorf("geographic %v: want earth fixed %v, got %v", test.vG, test.wantE, got)
+		}
+	}
+}
+
+func TestLookAngles(t *testing.T) {
+	earth := NewEarth()
+	observer := earth.GeocentricFromDegrees(-58.4, -34.6, 25)
+	above := observer
+	above.Elev += 1000
+	_, elev, rng := observer.LookAngles(above)
+	if !md1.EqualWithinAbs(elev, math.Pi/2, 1e-9) || !md1.EqualWithinAbs(rng, 1000, 1e-6) {
+		t.Errorf("point above: want elev=pi/2 range=1000, got elev=%g range=%g", elev, rng)
+	}
+
+	north := observer
+	north.Lat += 1e-3
+	az, elev, _ := observer.LookAngles(north)
+	if !md1.EqualWithinAbs(az, 0, 1e-6) && !md1.EqualWithinAbs(az, 2*math.Pi, 1e-6) {
+		t.Errorf("point north: want azimuth=0, got %g", az)
+	}
+	if elev >= 0 {
+		t.Errorf("point north at same elevation should be below horizon, got elev=%g", elev)
+	}
+	east := observer
+	east.Long += 1e-3
+	az, _, _ = observer.LookAngles(east)
+	if !md1.EqualWithinAbs(az, math.Pi/2, 1e-3) {
+		t.Errorf("point east: want azimuth=pi/2, got %g", az)
+	}
+
+	const wantAz, wantEl, wantRange = 2.1, 0.3, 850e3
+	target := observer.FromLookAngles(wantAz, wantEl, wantRange)
+	az, elev, rng = observer.LookAngles(target)
+	if !md1.EqualWithinAbs(az, wantAz, 1e-9) || !md1.EqualWithinAbs(elev, wantEl, 1e-9) || !md1.EqualWithinAbs(rng, wantRange, 1e-6) {
+		t.Errorf("round trip: want (%g,%g,%g), got (%g,%g,%g)", wantAz, wantEl, wantRange, az, elev, rng)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for target on a different world")
+		}
+	}()
+	observer.LookAngles(NewEarth().GeocentricFromDegrees(-58.4, -34.6, 1000))
+}
+
+func TestZonalGravity(t *testing.T) {
+	// Compare gravity against numerical gradient of J2 gravitational potential.
+	for _, w := range []*World{NewEarth(), NewMoon(), NewMars()} {
+		potential := func(r, lat float64) float64 {
+			s := math.Sin(lat)
+			ar := w.SemiMajorAxis / r
+			return w.G() / r * (1 - w.J2*ar*ar*(3*s*s-1)/2)
+		}
+		for _, latDeg := range []float64{-80, -45, 0, 30, 60} {
+			g := w.GeocentricFromDegrees(20, latDeg, 10e3).Geodesic()
+			r, lat := g.c.Radius(), g.c.Lat
+			const hLat, hR = 1e-6, 1.
+			wantNorth := (potential(r, lat+hLat) - potential(r, lat-hLat)) / (2 * hLat) / r
+			wantDown := -(potential(r+hR, lat) - potential(r-hR, lat)) / (2 * hR)
+			got := g.AGravG()
+			if !md1.EqualWithinAbs(got.X, wantNorth, 1e-7) || !md1.EqualWithinAbs(got.Z, wantDown, 1e-7) || got.Y != 0 {
+				t.Errorf("lat=%g: want (%g,0,%g), got %v", latDeg, wantNorth, wantDown, got)
+			}
+		}
+	}
+}
+
+func TestTEI(t *testing.T) {
+	cfg := MarsConfig()
+	cfg.CelestialLong = 0.3
+	w, err := NewWorld(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := w.GeocentricFromDegrees(30, 0, 0)
+	for _, tm := range []float64{0, 1000, w.Day() / 4, w.Day()} {
+		// The prime meridian is at the celestial longitude from inertial X at epoch time zero.
+		SBI, _ := g.InertialCoords(tm)
+		want := math.Remainder(w.Rotation*tm+cfg.CelestialLong+30*math.Pi/180, 2*math.Pi)
+		if got := math.Atan2(SBI.Y, SBI.X); !md1.EqualWithinAbs(got, want, 1e-12) {
+			t.Errorf("t=%g: want inertial longitude %g, got %g", tm, want, got)
+		}
+		back := w.GeocentricFromEarthFixedCoords(md3.MulMatVec(w.TEI(tm), SBI))
+		if !md1.EqualWithinAbs(back.Long, g.Long, 1e-12) || !md1.EqualWithinAbs(back.Lat, g.Lat, 1e-12) {
+			t.Errorf("t=%g: round trip want (%g,%g), got (%g,%g)", tm, g.Long, g.Lat, back.Long, back.Lat)
+		}
+	}
+}